@@ -2,32 +2,92 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
+	"github.com/andresgallo/evida_backend_go/internal/alerts"
+	"github.com/andresgallo/evida_backend_go/internal/anomaly"
 	"github.com/andresgallo/evida_backend_go/internal/api"
+	"github.com/andresgallo/evida_backend_go/internal/events"
 	"github.com/andresgallo/evida_backend_go/internal/fetcher"
 	"github.com/andresgallo/evida_backend_go/internal/geometry"
 	"github.com/andresgallo/evida_backend_go/internal/manager"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+	"github.com/andresgallo/evida_backend_go/internal/store"
+	"github.com/andresgallo/evida_backend_go/internal/stream"
+	"github.com/andresgallo/evida_backend_go/internal/threat"
+	"github.com/andresgallo/evida_backend_go/internal/tsunami"
 	"github.com/andresgallo/evida_backend_go/internal/websocket"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 const (
 	// Intervalo de actualización de datos (cada 2 minutos)
 	fetchInterval = 2 * time.Minute
 
-	// Tiempo máximo para mantener sismos en memoria (7 días)
-	maxEarthquakeAge = 7 * 24 * time.Hour
+	// Tiempo máximo para mantener sismos almacenados (30 días)
+	maxEarthquakeAge = 30 * 24 * time.Hour
 
 	// Intervalo de limpieza de sismos antiguos (cada hora)
 	cleanupInterval = 1 * time.Hour
 
 	// Puerto del servidor
 	serverPort = ":8080"
+
+	// Almacenamiento: "bolt" (base de datos en disco) o "memory" (en memoria, persistido con snapshots)
+	storeBackend = "bolt"
+
+	// Archivo de la base de datos de sismos
+	storePath = "data/evida.db"
+
+	// Políticas de retención por magnitud, región y amenaza (opcional)
+	retentionPoliciesPath = "config/retention.json"
+
+	// Archivo de sismos vencidos ("" = eliminarlos sin archivar)
+	archivePath = "data/archive.db"
+
+	// Snapshot del estado del gestor (sismos, revisiones y salud de las fuentes)
+	snapshotPath = "data/snapshot.gob.gz"
+
+	// Intervalo para guardar snapshots (cada 10 minutos)
+	snapshotInterval = 10 * time.Minute
+
+	// Intervalo para recalcular las secuencias sísmicas si cambiaron los sismos
+	sequenceInterval = 15 * time.Second
+
+	// Retraso máximo entre el origen de un sismo y su recepción para notificarlo
+	freshEventWindow = 1 * time.Hour
+
+	// Puntos de pronóstico de tsunami (opcional, si no existe se usan los por defecto)
+	forecastPointsPath = "config/forecast_points.json"
+
+	// Matriz de decisión de amenaza de tsunami (opcional, si no existe se usa la por defecto)
+	threatRulesPath = "config/threat_rules.json"
+
+	// Reglas de alerta (opcional); los cambios hechos por la API se guardan aquí
+	alertRulesPath = "config/alert_rules.json"
+
+	// Registro de alertas generadas ("" = solo las recientes en memoria)
+	alertStorePath = "data/alerts.db"
+
+	// Variable de entorno con el token para modificar reglas de alerta (sin token se rechazan los cambios)
+	adminTokenEnv = "EVIDA_ADMIN_TOKEN"
+
+	// Intervalo de evaluación de anomalías de sismicidad
+	anomalyInterval = 1 * time.Minute
+
+	// Eventos en cola por suscriptor del bus antes de empezar a descartar
+	subscriberBuffer = 100
+
+	// Mensajes SSE guardados para reanudar conexiones con Last-Event-ID
+	streamHistorySize = 500
 )
 
 func main() {
@@ -39,10 +99,74 @@ func main() {
 		log.Fatalf("❌ Error cargando datos de regiones: %v", err)
 	}
 
+	// Cargar puntos de pronóstico de tsunami
+	if points, err := tsunami.LoadForecastPoints(forecastPointsPath); err == nil {
+		tsunami.Configure(nil, points)
+		log.Printf("✅ %d puntos de pronóstico de tsunami cargados", len(points))
+	} else if !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("❌ Error cargando puntos de pronóstico: %v", err)
+	} else {
+		log.Printf("✅ Usando %d puntos de pronóstico de tsunami por defecto", len(tsunami.ForecastPoints()))
+	}
+
+	// Cargar matriz de amenaza de tsunami
+	if rules, err := threat.LoadRules(threatRulesPath); err == nil {
+		threat.Configure(rules)
+		log.Printf("✅ %d reglas de amenaza de tsunami cargadas", len(rules))
+	} else if !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("❌ Error cargando reglas de amenaza: %v", err)
+	} else {
+		log.Printf("✅ Usando %d reglas de amenaza de tsunami por defecto", len(threat.Rules()))
+	}
+
+	// Abrir almacenamiento
+	if err := os.MkdirAll(filepath.Dir(storePath), 0755); err != nil {
+		log.Fatalf("❌ Error creando directorio de datos: %v", err)
+	}
+	earthquakeStore, err := openStore()
+	if err != nil {
+		log.Fatalf("❌ Error abriendo almacenamiento: %v", err)
+	}
+	defer earthquakeStore.Close()
+
 	// Crear gestor de sismos
-	earthquakeManager := manager.NewEarthquakeManager(maxEarthquakeAge)
+	earthquakeManager := manager.NewEarthquakeManager(earthquakeStore, maxEarthquakeAge)
+	earthquakeManager.SetFreshWindow(freshEventWindow)
+	if err := earthquakeManager.RegisterMetrics(prometheus.DefaultRegisterer); err != nil {
+		log.Fatalf("❌ Error registrando métricas del gestor: %v", err)
+	}
 	log.Println("✅ Gestor de sismos inicializado")
 
+	// Políticas de retención y archivo de sismos vencidos
+	if policies, err := manager.LoadRetentionPolicies(retentionPoliciesPath); err == nil {
+		earthquakeManager.SetRetentionPolicies(policies)
+		log.Printf("✅ %d políticas de retención cargadas", len(policies))
+	} else if !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("❌ Error cargando políticas de retención: %v", err)
+	}
+	if archivePath != "" {
+		archiveStore, err := store.NewBoltStore(archivePath)
+		if err != nil {
+			log.Fatalf("❌ Error abriendo archivo de sismos: %v", err)
+		}
+		defer archiveStore.Close()
+		earthquakeManager.SetArchive(archiveStore)
+		log.Printf("✅ Sismos vencidos se archivan en %s", archivePath)
+	}
+
+	// Restaurar el último snapshot antes de la primera consulta a las fuentes
+	if restored, err := earthquakeManager.LoadSnapshot(snapshotPath); err == nil {
+		log.Printf("✅ Snapshot restaurado: %d sismos", restored)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		log.Printf("⚠️  Error restaurando snapshot: %v", err)
+	}
+
+	// Guardar snapshots periódicamente
+	earthquakeManager.StartSnapshots(snapshotPath, snapshotInterval)
+
+	// Recalcular las secuencias sísmicas en segundo plano
+	earthquakeManager.StartSequences(sequenceInterval)
+
 	// Iniciar limpieza automática de sismos antiguos
 	earthquakeManager.StartCleanup(cleanupInterval)
 	log.Println("✅ Limpieza automática configurada")
@@ -52,6 +176,60 @@ func main() {
 	go hub.Run()
 	log.Println("✅ Hub WebSocket iniciado")
 
+	// Crear broker de Server-Sent Events; recibe los mismos mensajes que el WebSocket
+	// y numera sus eventos con la secuencia de cambios del gestor
+	streamBroker := stream.NewBroker(streamHistorySize, subscriberBuffer, earthquakeManager.NextSeq)
+	broadcast := func(messageType string, data interface{}) {
+		hub.Broadcast(messageType, data)
+		streamBroker.Broadcast(messageType, data)
+	}
+
+	// Iniciar notificaciones de WebSocket y SSE (antes de la recolección para no perder eventos)
+	wsSubscription, err := earthquakeManager.Events().Subscribe("websocket", subscriberBuffer)
+	if err != nil {
+		log.Fatalf("❌ Error suscribiendo notificaciones WebSocket: %v", err)
+	}
+	go startNotifications(wsSubscription, broadcast)
+	log.Println("✅ Sistema de notificaciones iniciado")
+
+	// Iniciar motor de alertas
+	alertRules, err := alerts.LoadRules(alertRulesPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("❌ Error cargando reglas de alerta: %v", err)
+	}
+	alertEngine := alerts.NewEngine(alertRules, alertRulesPath)
+	if alertStorePath != "" {
+		alertStore, err := alerts.OpenStore(alertStorePath)
+		if err != nil {
+			log.Fatalf("❌ Error abriendo registro de alertas: %v", err)
+		}
+		defer alertStore.Close()
+		if err := alertEngine.SetStore(alertStore); err != nil {
+			log.Fatalf("❌ Error leyendo registro de alertas: %v", err)
+		}
+	}
+	alertEngine.SetNotifier(func(alert alerts.Alert) {
+		broadcast("alert", alert)
+	})
+	alertSubscription, err := earthquakeManager.Events().Subscribe("alerts", subscriberBuffer)
+	if err != nil {
+		log.Fatalf("❌ Error suscribiendo el motor de alertas: %v", err)
+	}
+	go alertEngine.Run(alertSubscription)
+	log.Printf("✅ Motor de alertas iniciado con %d reglas", len(alertRules))
+
+	// Iniciar detección de anomalías de sismicidad
+	anomalyDetector := anomaly.NewDetector(anomaly.DefaultConfig)
+	anomalyDetector.SetNotifier(func(a anomaly.Anomaly) {
+		broadcast("seismicity_anomaly", a)
+	})
+	// Se evalúan los sismos de la ventana reciente y del fondo, un reporte por evento
+	anomalyHorizon := anomalyDetector.Config().Window + anomalyDetector.Config().Background
+	anomalyDetector.Start(func() []models.Earthquake {
+		return earthquakeManager.GetSeismicity(anomalyHorizon)
+	}, anomalyInterval)
+	log.Println("✅ Detección de anomalías de sismicidad iniciada")
+
 	// Crear fetchers
 	usgsFetcher := fetcher.NewUSGSFetcher()
 	geofonFetcher := fetcher.NewGEOFONFetcher()
@@ -71,29 +249,41 @@ func main() {
 	go startDataCollection(ctx, fetchers, earthquakeManager, hub)
 	log.Println("✅ Recolección de datos iniciada")
 
-	// Iniciar notificaciones de WebSocket
-	go startWebSocketNotifications(earthquakeManager, hub)
-	log.Println("✅ Sistema de notificaciones iniciado")
-
 	// Configurar servidor HTTP
-	server := api.NewServer(earthquakeManager, hub)
+	server := api.NewServer(earthquakeManager, hub, streamBroker, alertEngine, anomalyDetector)
+	if token := os.Getenv(adminTokenEnv); token != "" {
+		server.SetAdminToken(token)
+	} else {
+		log.Printf("⚠️  %s no definido: las reglas de alerta no se pueden modificar por la API", adminTokenEnv)
+	}
 	mux := server.SetupRoutes()
 
+	// Contexto base de las solicitudes: se cancela al apagar para cerrar las
+	// conexiones SSE, que de otro modo Shutdown esperaría hasta el timeout
+	requestCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+
 	httpServer := &http.Server{
 		Addr:         serverPort,
-		Handler:      mux,
+		Handler:      api.Instrument(mux),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return requestCtx
+		},
 	}
+	httpServer.RegisterOnShutdown(cancelRequests)
 
 	// Iniciar servidor en goroutine
 	go func() {
 		log.Printf("🚀 Servidor HTTP escuchando en %s", serverPort)
 		log.Println("   - WebSocket: ws://localhost:8080/ws")
+		log.Println("   - SSE: http://localhost:8080/api/stream")
 		log.Println("   - API: http://localhost:8080/api/earthquakes")
 		log.Println("   - Stats: http://localhost:8080/api/stats")
 		log.Println("   - Health: http://localhost:8080/api/health")
+		log.Println("   - Metrics: http://localhost:8080/metrics")
 
 		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Error iniciando servidor: %v", err)
@@ -115,13 +305,38 @@ func main() {
 		log.Printf("Error apagando servidor: %v", err)
 	}
 
+	// Detener la recolección y guardar el estado para el próximo arranque
+	cancel()
+	if err := earthquakeManager.SaveSnapshot(snapshotPath); err != nil {
+		log.Printf("⚠️  Error guardando snapshot: %v", err)
+	} else {
+		log.Printf("💾 Snapshot guardado en %s", snapshotPath)
+	}
+
 	log.Println("✅ Servidor apagado correctamente")
 }
 
+// openStore abre el almacenamiento configurado en storeBackend
+func openStore() (store.Store, error) {
+	if storeBackend == "memory" {
+		log.Println("✅ Almacenamiento en memoria (persistido con snapshots)")
+		return store.NewMemoryStore(), nil
+	}
+
+	boltStore, err := store.NewBoltStore(storePath)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("✅ Almacenamiento abierto en %s", storePath)
+	return boltStore, nil
+}
+
 // startDataCollection inicia la recolección periódica de datos de sismos
 func startDataCollection(ctx context.Context, fetchers []fetcher.Fetcher, manager *manager.EarthquakeManager, hub *websocket.Hub) {
-	// Ejecutar inmediatamente al inicio
+	// Sincronización inicial: las fuentes devuelven la última semana completa; los
+	// sismos fuera de la ventana de sismos nuevos se guardan como históricos
 	fetchAllData(fetchers, manager)
+	log.Println("✅ Sincronización inicial completada")
 
 	// Luego ejecutar periódicamente
 	ticker := time.NewTicker(fetchInterval)
@@ -143,10 +358,12 @@ func fetchAllData(fetchers []fetcher.Fetcher, manager *manager.EarthquakeManager
 	log.Println("🔄 Obteniendo datos de sismos...")
 
 	totalNew := 0
-	for i, f := range fetchers {
+	for _, f := range fetchers {
+		started := time.Now()
 		earthquakes, err := f.Fetch()
+		manager.RecordFetch(f.Name(), len(earthquakes), time.Since(started), err)
 		if err != nil {
-			log.Printf("⚠️  Error fetching from source %d: %v", i+1, err)
+			log.Printf("⚠️  Error fetching from %s: %v", f.Name(), err)
 			continue
 		}
 
@@ -154,7 +371,7 @@ func fetchAllData(fetchers []fetcher.Fetcher, manager *manager.EarthquakeManager
 		totalNew += len(newOnes)
 
 		if len(newOnes) > 0 {
-			log.Printf("   ➕ Fuente %d: %d nuevos sismos de %d totales", i+1, len(newOnes), len(earthquakes))
+			log.Printf("   ➕ %s: %d nuevos sismos de %d totales", f.Name(), len(newOnes), len(earthquakes))
 		}
 	}
 
@@ -167,13 +384,16 @@ func fetchAllData(fetchers []fetcher.Fetcher, manager *manager.EarthquakeManager
 	log.Printf("   📊 Total en memoria: %d sismos", manager.GetCount())
 }
 
-// startWebSocketNotifications escucha nuevos sismos y los envía por WebSocket
-func startWebSocketNotifications(manager *manager.EarthquakeManager, hub *websocket.Hub) {
-	earthquakeChan := manager.GetNewEarthquakeChannel()
+// startNotifications escucha nuevos sismos y los envía por WebSocket y SSE
+func startNotifications(sub *events.Subscription, broadcast func(messageType string, data interface{})) {
+	for event := range sub.Events() {
+		if !event.Notifiable() {
+			continue
+		}
 
-	for eq := range earthquakeChan {
+		eq := event.Earthquake
 		log.Printf("🔔 Nuevo sismo detectado: M%.1f - %s [%s %s]",
 			eq.Magnitude, eq.Location, eq.Oceano, eq.OceanoRegion)
-		hub.BroadcastEarthquake(eq)
+		broadcast("new_earthquake", eq)
 	}
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
+)
+
+// validate-regions revisa un archivo de regiones antes de desplegarlo
+// Sale con código 1 si encuentra errores (anillos inválidos, cruces, superposiciones).
+func main() {
+	filePath := flag.String("file", "internal/geometry/datosLC.json", "Archivo de regiones a validar")
+	gapKm := flag.Float64("gap-km", 2, "Distancia máxima (km) para reportar huecos entre zonas vecinas (0 desactiva)")
+	asJSON := flag.Bool("json", false, "Imprimir el reporte en formato JSON")
+	flag.Parse()
+
+	report, err := geometry.ValidateRegionFile(*filePath, geometry.ValidationOptions{
+		GapToleranceKm: *gapKm,
+	})
+	if err != nil {
+		log.Fatalf("❌ Error validando %s: %v", *filePath, err)
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("❌ Error generando JSON: %v", err)
+		}
+	} else {
+		printReport(*filePath, report)
+	}
+
+	if report.Errors > 0 {
+		os.Exit(1)
+	}
+}
+
+// printReport imprime el reporte en formato legible
+func printReport(filePath string, report *geometry.ValidationReport) {
+	fmt.Printf("🔍 %s (versión %.12s)\n", filePath, report.Version)
+
+	for _, issue := range report.Issues {
+		icon := "⚠️ "
+		if issue.Severity == geometry.SeverityError {
+			icon = "❌"
+		}
+
+		location := ""
+		if issue.Location != nil {
+			location = fmt.Sprintf(" @ [%.5f, %.5f]", issue.Location.Lat, issue.Location.Lon)
+		}
+
+		fmt.Printf("%s %s[%d] %s: %s%s\n", icon, issue.Layer, issue.Polygon, issue.Check, issue.Message, location)
+	}
+
+	if len(report.Issues) == 0 {
+		fmt.Println("✅ Sin problemas")
+		return
+	}
+	fmt.Printf("📊 %d errores, %d advertencias\n", report.Errors, report.Warnings)
+}
@@ -0,0 +1,247 @@
+package alerts
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/events"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// maxAlerts es el número de alertas recientes que se guardan en memoria
+// (el registro completo queda en el Store, si está configurado)
+const maxAlerts = 1000
+
+// Alert es el registro de una regla que se cumplió para un sismo
+type Alert struct {
+	ID         string            `json:"id"`
+	RuleID     string            `json:"ruleId"`
+	RuleName   string            `json:"ruleName,omitempty"`
+	EventType  events.Type       `json:"eventType"` // created o updated
+	Earthquake models.Earthquake `json:"earthquake"`
+	CreatedAt  time.Time         `json:"createdAt"`
+}
+
+// SaveError indica que las reglas no se pudieron guardar en el archivo
+type SaveError struct {
+	Err error
+}
+
+func (e *SaveError) Error() string {
+	return fmt.Sprintf("error saving alert rules: %v", e.Err)
+}
+
+func (e *SaveError) Unwrap() error {
+	return e.Err
+}
+
+// Engine guarda las reglas y evalúa los eventos del bus
+type Engine struct {
+	mu        sync.RWMutex
+	rules     []Rule
+	rulesPath string  // Archivo donde se guardan los cambios hechos por la API ("" = no guardar)
+	alerts    []Alert // Más antigua primero
+	store     *Store  // Registro persistente de alertas (nil = solo en memoria)
+	notify    func(Alert)
+}
+
+// NewEngine crea un motor con las reglas dadas
+// Los cambios de reglas se guardan en rulesPath si no está vacío.
+func NewEngine(rules []Rule, rulesPath string) *Engine {
+	return &Engine{
+		rules:     rules,
+		rulesPath: rulesPath,
+	}
+}
+
+// SetStore guarda las alertas nuevas en st y carga las más recientes ya guardadas
+func (e *Engine) SetStore(st *Store) error {
+	recent, err := st.Recent(maxAlerts)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.store = st
+	e.alerts = make([]Alert, 0, len(recent))
+	for i := len(recent) - 1; i >= 0; i-- {
+		e.alerts = append(e.alerts, recent[i])
+	}
+	return nil
+}
+
+// SetNotifier define la función llamada con cada alerta nueva (ej: envío por WebSocket)
+func (e *Engine) SetNotifier(notify func(Alert)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.notify = notify
+}
+
+// Run evalúa los eventos de la suscripción hasta que se cierre
+func (e *Engine) Run(sub *events.Subscription) {
+	for event := range sub.Events() {
+		e.Evaluate(event)
+	}
+}
+
+// Evaluate evalúa un evento contra todas las reglas y retorna las alertas generadas
+// Se evalúan los sismos creados y actualizados que no son históricos; los no
+// categorizados solo contra las reglas que los piden explícitamente. En una
+// actualización solo alertan las reglas que la versión anterior no cumplía, salvo
+// que la anterior fuera histórica (nunca alertó).
+func (e *Engine) Evaluate(event events.Event) []Alert {
+	if event.Type != events.Created && event.Type != events.Updated {
+		return nil
+	}
+	eq := event.Earthquake
+	if eq.Historical {
+		return nil
+	}
+
+	e.mu.Lock()
+	var created []Alert
+	for _, rule := range e.rules {
+		if !eq.IsCategorized() && !rule.TargetsUncategorized() {
+			continue
+		}
+		if !rule.Matches(eq) {
+			continue
+		}
+		if event.Previous != nil && !event.Previous.Historical && rule.Matches(*event.Previous) {
+			continue
+		}
+
+		alert := Alert{
+			ID:         fmt.Sprintf("%s:%s:%d", rule.ID, eq.ID, eq.Revision),
+			RuleID:     rule.ID,
+			RuleName:   rule.Name,
+			EventType:  event.Type,
+			Earthquake: eq,
+			CreatedAt:  time.Now(),
+		}
+		created = append(created, alert)
+		e.alerts = append(e.alerts, alert)
+	}
+	if len(e.alerts) > maxAlerts {
+		e.alerts = e.alerts[len(e.alerts)-maxAlerts:]
+	}
+	notify := e.notify
+	st := e.store
+	e.mu.Unlock()
+
+	for _, alert := range created {
+		log.Printf("🚨 Alerta %s: M%.1f - %s [%s]", alert.RuleID, eq.Magnitude, eq.Location, eq.ID)
+		if st != nil {
+			if err := st.Save(alert); err != nil {
+				log.Printf("⚠️  Error guardando alerta %s: %v", alert.ID, err)
+			}
+		}
+		if notify != nil {
+			notify(alert)
+		}
+	}
+	return created
+}
+
+// Alerts retorna las alertas recientes (la más reciente primero), hasta limit (0 = todas)
+func (e *Engine) Alerts(limit int) []Alert {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	count := len(e.alerts)
+	if limit > 0 && limit < count {
+		count = limit
+	}
+
+	result := make([]Alert, 0, count)
+	for i := len(e.alerts) - 1; i >= 0 && len(result) < count; i-- {
+		result = append(result, e.alerts[i])
+	}
+	return result
+}
+
+// Rules retorna una copia de las reglas configuradas
+func (e *Engine) Rules() []Rule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.copyRules()
+}
+
+// AddRule agrega una regla nueva
+func (e *Engine) AddRule(rule Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.indexOf(rule.ID) >= 0 {
+		return ErrRuleExists
+	}
+	return e.save(append(e.copyRules(), rule))
+}
+
+// UpdateRule reemplaza una regla existente
+func (e *Engine) UpdateRule(rule Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	i := e.indexOf(rule.ID)
+	if i < 0 {
+		return ErrRuleNotFound
+	}
+	rules := e.copyRules()
+	rules[i] = rule
+	return e.save(rules)
+}
+
+// DeleteRule elimina una regla
+func (e *Engine) DeleteRule(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	i := e.indexOf(id)
+	if i < 0 {
+		return ErrRuleNotFound
+	}
+	rules := e.copyRules()
+	rules = append(rules[:i], rules[i+1:]...)
+	return e.save(rules)
+}
+
+// copyRules copia las reglas (requiere el candado)
+func (e *Engine) copyRules() []Rule {
+	rules := make([]Rule, len(e.rules))
+	copy(rules, e.rules)
+	return rules
+}
+
+// indexOf retorna la posición de la regla con el ID dado o -1 (requiere el candado)
+func (e *Engine) indexOf(id string) int {
+	for i, rule := range e.rules {
+		if rule.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// save guarda las reglas en el archivo y luego las activa (requiere el candado de escritura)
+// Si no se pueden guardar, las reglas en uso no cambian.
+func (e *Engine) save(rules []Rule) error {
+	if e.rulesPath != "" {
+		if err := SaveRules(e.rulesPath, rules); err != nil {
+			return &SaveError{Err: err}
+		}
+	}
+	e.rules = rules
+	return nil
+}
@@ -0,0 +1,40 @@
+package alerts
+
+import (
+	"testing"
+
+	"github.com/andresgallo/evida_backend_go/internal/events"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestEvaluateUncategorized(t *testing.T) {
+	categorized := models.Earthquake{ID: "cat", Magnitude: 6, Oceano: "Pacifico", OceanoRegion: "local"}
+	uncategorized := models.Earthquake{ID: "unc", Magnitude: 6, Oceano: models.Uncategorized, OceanoRegion: models.Uncategorized}
+
+	tests := []struct {
+		name  string
+		rule  Rule
+		eq    models.Earthquake
+		alert bool
+	}{
+		{"categorizado", Rule{ID: "m5", MinMagnitude: ptr(5)}, categorized, true},
+		{"no categorizado sin pedirlo", Rule{ID: "m5", MinMagnitude: ptr(5)}, uncategorized, false},
+		{"no categorizado por océano", Rule{ID: "unc", Oceanos: []string{"uncategorized"}}, uncategorized, true},
+		{"no categorizado por región", Rule{ID: "unc", Regions: []string{models.Uncategorized}}, uncategorized, true},
+		{"regla de no categorizados", Rule{ID: "unc", Oceanos: []string{models.Uncategorized}}, categorized, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine([]Rule{tt.rule}, "")
+			got := engine.Evaluate(events.Event{Type: events.Created, Earthquake: tt.eq})
+			if (len(got) > 0) != tt.alert {
+				t.Errorf("Evaluate() = %d alerts, want alert %v", len(got), tt.alert)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package alerts evalúa reglas de alerta definidas por los administradores contra
+// cada sismo nuevo o actualizado y guarda un registro por cada regla que se cumple.
+// Las reglas se cargan desde un archivo JSON y se pueden editar por la API.
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+	"github.com/andresgallo/evida_backend_go/internal/threat"
+)
+
+// ErrRuleNotFound se retorna al modificar o eliminar una regla que no existe
+var ErrRuleNotFound = errors.New("alert rule not found")
+
+// ErrRuleExists se retorna al crear una regla con un ID en uso
+var ErrRuleExists = errors.New("alert rule already exists")
+
+// Area es un círculo alrededor de un punto
+type Area struct {
+	Name     string  `json:"name,omitempty"` // Ej: "San Andrés"
+	Lat      float64 `json:"lat"`
+	Lon      float64 `json:"lon"`
+	RadiusKm float64 `json:"radiusKm"`
+}
+
+// Rule es una regla de alerta; todas las condiciones presentes deben cumplirse
+// Los mínimos son inclusivos y los máximos exclusivos (depth < 70 km).
+type Rule struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name,omitempty"`
+	Disabled       bool     `json:"disabled,omitempty"`
+	MinMagnitude   *float64 `json:"minMagnitude,omitempty"`
+	MaxMagnitude   *float64 `json:"maxMagnitude,omitempty"`
+	MinDepth       *float64 `json:"minDepth,omitempty"`
+	MaxDepth       *float64 `json:"maxDepth,omitempty"`
+	Oceanos        []string `json:"oceanos,omitempty"`
+	Regions        []string `json:"regions,omitempty"`
+	Sources        []string `json:"sources,omitempty"`
+	Near           *Area    `json:"near,omitempty"`
+	MinThreatLevel string   `json:"minThreatLevel,omitempty"` // information, advisory, watch, warning
+}
+
+// Validate verifica que la regla se pueda evaluar
+func (r Rule) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("rule id is required")
+	}
+	if strings.ContainsAny(r.ID, "/?#") {
+		return fmt.Errorf("rule id %q contains invalid characters", r.ID)
+	}
+	if r.Near != nil {
+		if r.Near.Lat < -90 || r.Near.Lat > 90 || r.Near.Lon < -180 || r.Near.Lon > 180 {
+			return fmt.Errorf("rule %q has invalid near coordinates", r.ID)
+		}
+		if r.Near.RadiusKm <= 0 {
+			return fmt.Errorf("rule %q needs a positive near.radiusKm", r.ID)
+		}
+	}
+	if r.MinThreatLevel != "" && threat.Level(r.MinThreatLevel).Rank() < 0 {
+		return fmt.Errorf("rule %q has invalid minThreatLevel %q", r.ID, r.MinThreatLevel)
+	}
+	return nil
+}
+
+// Matches indica si el sismo cumple todas las condiciones de la regla
+func (r Rule) Matches(eq models.Earthquake) bool {
+	if r.Disabled {
+		return false
+	}
+	if r.MinMagnitude != nil && eq.Magnitude < *r.MinMagnitude {
+		return false
+	}
+	if r.MaxMagnitude != nil && eq.Magnitude >= *r.MaxMagnitude {
+		return false
+	}
+	if r.MinDepth != nil && eq.Depth < *r.MinDepth {
+		return false
+	}
+	if r.MaxDepth != nil && eq.Depth >= *r.MaxDepth {
+		return false
+	}
+	if len(r.Oceanos) > 0 && !containsFold(r.Oceanos, eq.Oceano) {
+		return false
+	}
+	if len(r.Regions) > 0 && !containsFold(r.Regions, eq.OceanoRegion) {
+		return false
+	}
+	if len(r.Sources) > 0 && !containsFold(r.Sources, eq.Source) {
+		return false
+	}
+	if r.MinThreatLevel != "" &&
+		threat.Level(eq.ThreatLevel).Rank() < threat.Level(r.MinThreatLevel).Rank() {
+		return false
+	}
+	if r.Near != nil {
+		center := models.Point{Lat: r.Near.Lat, Lon: r.Near.Lon}
+		epicenter := models.Point{Lat: eq.Latitude, Lon: eq.Longitude}
+		if geometry.Distance(center, epicenter) > r.Near.RadiusKm {
+			return false
+		}
+	}
+	return true
+}
+
+// TargetsUncategorized indica si la regla pide explícitamente sismos no categorizados
+// (océano o región "Uncategorized"); las demás reglas no los evalúan
+func (r Rule) TargetsUncategorized() bool {
+	return containsFold(r.Oceanos, models.Uncategorized) || containsFold(r.Regions, models.Uncategorized)
+}
+
+// LoadRules carga las reglas desde un archivo JSON
+func LoadRules(filePath string) ([]Rule, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return nil, err
+		}
+		if seen[rule.ID] {
+			return nil, fmt.Errorf("duplicate rule id %q", rule.ID)
+		}
+		seen[rule.ID] = true
+	}
+	return rules, nil
+}
+
+// SaveRules guarda las reglas en un archivo JSON (escritura atómica)
+func SaveRules(filePath string, rules []Rule) error {
+	data, err := json.MarshalIndent(rules, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// containsFold indica si la lista contiene el valor (sin distinguir mayúsculas)
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
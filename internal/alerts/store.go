@@ -0,0 +1,108 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// maxStoredAlerts es el número de alertas que se conservan en el archivo; al
+// superarlo se eliminan las más antiguas
+const maxStoredAlerts = 100000
+
+// Alertas codificadas con gob, por momento de creación (8 bytes) + ID
+var alertsBucket = []byte("alerts")
+
+// Store guarda el registro de alertas en un archivo bbolt para que sobreviva a
+// los reinicios
+type Store struct {
+	db    *bolt.DB
+	mu    sync.Mutex
+	count int // Alertas guardadas
+}
+
+// OpenStore abre (o crea) el archivo de alertas en la ruta indicada
+func OpenStore(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening alert store %s: %w", path, err)
+	}
+
+	count := 0
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(alertsBucket)
+		if err != nil {
+			return err
+		}
+		count = bucket.Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating alert bucket: %w", err)
+	}
+
+	return &Store{db: db, count: count}, nil
+}
+
+// Save agrega una alerta y elimina las más antiguas si se supera maxStoredAlerts
+func (s *Store) Save(alert Alert) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(alert); err != nil {
+		return fmt.Errorf("error encoding alert: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(alertsBucket)
+		if err := bucket.Put(alertKey(alert), buf.Bytes()); err != nil {
+			return err
+		}
+		s.count++
+
+		cursor := bucket.Cursor()
+		for k, _ := cursor.First(); k != nil && s.count > maxStoredAlerts; k, _ = cursor.Next() {
+			if err := cursor.Delete(); err != nil {
+				return err
+			}
+			s.count--
+		}
+		return nil
+	})
+}
+
+// Recent retorna las alertas guardadas (la más reciente primero), hasta limit (0 = todas)
+func (s *Store) Recent(limit int) ([]Alert, error) {
+	var result []Alert
+	err := s.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(alertsBucket).Cursor()
+		for k, v := cursor.Last(); k != nil && (limit <= 0 || len(result) < limit); k, v = cursor.Prev() {
+			var alert Alert
+			if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&alert); err != nil {
+				return fmt.Errorf("error decoding alert %s: %w", k[8:], err)
+			}
+			result = append(result, alert)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// Close cierra el archivo
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// alertKey ordena las alertas por momento de creación
+func alertKey(alert Alert) []byte {
+	key := make([]byte, 8, 8+len(alert.ID))
+	binary.BigEndian.PutUint64(key, uint64(alert.CreatedAt.UnixNano()))
+	return append(key, alert.ID...)
+}
@@ -0,0 +1,353 @@
+// Package analytics calcula estadísticas de un conjunto de sismos para el reporte
+// semanal: histogramas, momento sísmico y energía acumulados, magnitud de completitud
+// y valor b de Gutenberg-Richter por región.
+//
+// Compute espera un reporte por evento: si varias fuentes reportan el mismo sismo,
+// contarlo varias veces infla los conteos, el momento y el ajuste del valor b.
+//
+// Las magnitudes se tratan como Mw aunque las fuentes mezclan escalas (SGC reporta
+// ML para sismos pequeños); el valor b y Mc son indicativos.
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+const (
+	// magnitudeBinWidth es el ancho de los intervalos del histograma de magnitud
+	magnitudeBinWidth = 0.5
+
+	// fmdBinWidth es el ancho de los intervalos para Mc y el valor b
+	fmdBinWidth = 0.1
+
+	// mcCorrection se suma a la máxima curvatura, que tiende a subestimar Mc
+	mcCorrection = 0.2
+
+	// minBValueEvents es el mínimo de sismos sobre Mc para estimar el valor b
+	minBValueEvents = 20
+
+	// AllRegions es la clave del grupo con todos los sismos
+	AllRegions = "all"
+)
+
+// Report es el resultado del análisis
+type Report struct {
+	Start        time.Time              `json:"-"`
+	End          time.Time              `json:"-"`
+	Count        int                    `json:"count"`
+	Magnitudes   []MagnitudeBin         `json:"magnitudeHistogram"`
+	DepthClasses []DepthClass           `json:"depthClasses"`
+	ByHour       [24]int                `json:"byHourUTC"`
+	ByDay        []DaySummary           `json:"byDay"` // Con momento y energía acumulados
+	TotalMoment  float64                `json:"totalMomentNm"`
+	TotalEnergy  float64                `json:"totalEnergyJ"`
+	Regions      map[string]RegionStats `json:"regions"` // Incluye "all"
+}
+
+// MarshalJSON formatea el rango de tiempo igual que el tiempo de los sismos
+func (r Report) MarshalJSON() ([]byte, error) {
+	type Alias Report
+	return json.Marshal(&struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+		*Alias
+	}{
+		Start: r.Start.Format("2006-01-02 15:04:05"),
+		End:   r.End.Format("2006-01-02 15:04:05"),
+		Alias: (*Alias)(&r),
+	})
+}
+
+// MagnitudeBin es un intervalo [Min, Max) del histograma de magnitud
+type MagnitudeBin struct {
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Count int     `json:"count"`
+}
+
+// DepthClass cuenta los sismos de un rango de profundidad
+type DepthClass struct {
+	Name       string   `json:"name"`
+	MinDepthKm float64  `json:"minDepthKm"`
+	MaxDepthKm *float64 `json:"maxDepthKm,omitempty"` // Exclusivo; nil = sin límite
+	Count      int      `json:"count"`
+}
+
+// DaySummary resume un día (UTC)
+type DaySummary struct {
+	Date             string  `json:"date"`
+	Count            int     `json:"count"`
+	Moment           float64 `json:"momentNm"`
+	Energy           float64 `json:"energyJ"`
+	CumulativeMoment float64 `json:"cumulativeMomentNm"`
+	CumulativeEnergy float64 `json:"cumulativeEnergyJ"`
+}
+
+// RegionStats son las estadísticas de Gutenberg-Richter de un grupo de sismos
+type RegionStats struct {
+	Count        int       `json:"count"`
+	MaxMagnitude float64   `json:"maxMagnitude"`
+	Mc           *float64  `json:"mc,omitempty"`          // Magnitud de completitud (máxima curvatura + 0.2)
+	BValue       *float64  `json:"bValue,omitempty"`      // Aki-Utsu sobre los sismos con M >= Mc
+	BValueError  *float64  `json:"bValueError,omitempty"` // Desviación estándar de Shi y Bolt
+	BValueCI95   []float64 `json:"bValueCI95,omitempty"`  // [inferior, superior]
+	BValueEvents int       `json:"bValueEvents"`          // Sismos usados para el valor b
+	Note         string    `json:"note,omitempty"`
+}
+
+// depthClasses son los rangos de profundidad usados en el reporte
+var depthClasses = []struct {
+	name     string
+	min, max float64
+}{
+	{"shallow", 0, 70},
+	{"intermediate", 70, 300},
+	{"deep", 300, math.Inf(1)},
+}
+
+// SeismicMoment retorna el momento sísmico en N·m: M0 = 10^(1.5·Mw + 9.1)
+func SeismicMoment(magnitude float64) float64 {
+	return math.Pow(10, 1.5*magnitude+9.1)
+}
+
+// RadiatedEnergy retorna la energía radiada en julios: log10 E = 1.5·M + 4.8
+func RadiatedEnergy(magnitude float64) float64 {
+	return math.Pow(10, 1.5*magnitude+4.8)
+}
+
+// Compute analiza los sismos del rango [start, end)
+func Compute(earthquakes []models.Earthquake, start, end time.Time) Report {
+	report := Report{
+		Start:   start,
+		End:     end,
+		Regions: make(map[string]RegionStats),
+	}
+
+	var selected []models.Earthquake
+	for _, eq := range earthquakes {
+		if !eq.Time.Before(start) && eq.Time.Before(end) {
+			selected = append(selected, eq)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool {
+		return selected[i].Time.Before(selected[j].Time)
+	})
+	report.Count = len(selected)
+
+	report.Magnitudes = magnitudeHistogram(selected)
+	report.DepthClasses = depthHistogram(selected)
+
+	days := make(map[string]*DaySummary)
+	for _, eq := range selected {
+		report.ByHour[eq.Time.UTC().Hour()]++
+
+		date := eq.Time.UTC().Format("2006-01-02")
+		day, exists := days[date]
+		if !exists {
+			day = &DaySummary{Date: date}
+			days[date] = day
+		}
+		day.Count++
+		day.Moment += SeismicMoment(eq.Magnitude)
+		day.Energy += RadiatedEnergy(eq.Magnitude)
+	}
+	report.ByDay = cumulativeDays(days, start, end)
+	if len(report.ByDay) > 0 {
+		last := report.ByDay[len(report.ByDay)-1]
+		report.TotalMoment = last.CumulativeMoment
+		report.TotalEnergy = last.CumulativeEnergy
+	}
+
+	groups := map[string][]float64{AllRegions: nil}
+	for _, eq := range selected {
+		groups[AllRegions] = append(groups[AllRegions], eq.Magnitude)
+		key := models.Uncategorized
+		if eq.IsCategorized() {
+			key = fmt.Sprintf("%s %s", eq.Oceano, eq.OceanoRegion)
+		}
+		groups[key] = append(groups[key], eq.Magnitude)
+	}
+	for key, magnitudes := range groups {
+		report.Regions[key] = regionStats(magnitudes)
+	}
+
+	return report
+}
+
+// magnitudeHistogram cuenta los sismos en intervalos de magnitudeBinWidth
+func magnitudeHistogram(earthquakes []models.Earthquake) []MagnitudeBin {
+	bins := []MagnitudeBin{}
+	if len(earthquakes) == 0 {
+		return bins
+	}
+
+	minBin, maxBin := math.Inf(1), math.Inf(-1)
+	counts := make(map[int]int)
+	for _, eq := range earthquakes {
+		bin := int(math.Floor(eq.Magnitude/magnitudeBinWidth + 1e-9))
+		counts[bin]++
+		minBin = math.Min(minBin, float64(bin))
+		maxBin = math.Max(maxBin, float64(bin))
+	}
+
+	for bin := int(minBin); bin <= int(maxBin); bin++ {
+		bins = append(bins, MagnitudeBin{
+			Min:   float64(bin) * magnitudeBinWidth,
+			Max:   float64(bin+1) * magnitudeBinWidth,
+			Count: counts[bin],
+		})
+	}
+	return bins
+}
+
+// depthHistogram cuenta los sismos por clase de profundidad
+func depthHistogram(earthquakes []models.Earthquake) []DepthClass {
+	classes := make([]DepthClass, len(depthClasses))
+	for i, class := range depthClasses {
+		classes[i] = DepthClass{Name: class.name, MinDepthKm: class.min}
+		if !math.IsInf(class.max, 1) {
+			max := class.max
+			classes[i].MaxDepthKm = &max
+		}
+	}
+
+	for _, eq := range earthquakes {
+		for i, class := range depthClasses {
+			if eq.Depth < class.max || i == len(depthClasses)-1 {
+				classes[i].Count++
+				break
+			}
+		}
+	}
+	return classes
+}
+
+// cumulativeDays ordena los días e incluye los días sin sismos del rango
+func cumulativeDays(days map[string]*DaySummary, start, end time.Time) []DaySummary {
+	result := []DaySummary{}
+	if len(days) == 0 {
+		return result
+	}
+
+	// Recorrer todos los días del rango (desde el primer sismo si el rango no tiene inicio)
+	first := start.UTC().Format("2006-01-02")
+	if start.IsZero() {
+		first = ""
+		for date := range days {
+			if first == "" || date < first {
+				first = date
+			}
+		}
+	}
+	day, _ := time.Parse("2006-01-02", first)
+	last := end.Add(-time.Nanosecond).UTC()
+
+	var moment, energy float64
+	for !day.After(last) {
+		date := day.Format("2006-01-02")
+		summary := DaySummary{Date: date}
+		if d, exists := days[date]; exists {
+			summary = *d
+		}
+		moment += summary.Moment
+		energy += summary.Energy
+		summary.CumulativeMoment = moment
+		summary.CumulativeEnergy = energy
+		result = append(result, summary)
+		day = day.AddDate(0, 0, 1)
+	}
+	return result
+}
+
+// regionStats estima Mc y el valor b para un grupo de magnitudes
+func regionStats(magnitudes []float64) RegionStats {
+	stats := RegionStats{Count: len(magnitudes)}
+	if len(magnitudes) == 0 {
+		return stats
+	}
+	for _, m := range magnitudes {
+		stats.MaxMagnitude = math.Max(stats.MaxMagnitude, m)
+	}
+
+	mc, ok := MagnitudeOfCompleteness(magnitudes)
+	if !ok {
+		stats.Note = "insufficient events for Mc"
+		return stats
+	}
+	stats.Mc = &mc
+
+	b, sigma, n, ok := BValue(magnitudes, mc)
+	stats.BValueEvents = n
+	if !ok {
+		stats.Note = fmt.Sprintf("b-value needs at least %d events with M >= Mc", minBValueEvents)
+		return stats
+	}
+	stats.BValue = &b
+	stats.BValueError = &sigma
+	stats.BValueCI95 = []float64{b - 1.96*sigma, b + 1.96*sigma}
+	return stats
+}
+
+// MagnitudeOfCompleteness estima Mc con el método de máxima curvatura: el
+// intervalo de 0.1 con más sismos, más una corrección de 0.2
+func MagnitudeOfCompleteness(magnitudes []float64) (float64, bool) {
+	if len(magnitudes) < 2 {
+		return 0, false
+	}
+
+	counts := make(map[int]int)
+	bestBin, bestCount := 0, -1
+	for _, m := range magnitudes {
+		bin := int(math.Round(m / fmdBinWidth))
+		counts[bin]++
+	}
+	for bin, count := range counts {
+		if count > bestCount || (count == bestCount && bin < bestBin) {
+			bestBin, bestCount = bin, count
+		}
+	}
+
+	mc := float64(bestBin)*fmdBinWidth + mcCorrection
+	return math.Round(mc*10) / 10, true
+}
+
+// BValue estima el valor b con máxima verosimilitud (Aki 1965, corrección de
+// Utsu por intervalos de 0.1) sobre las magnitudes >= mc, con el error de Shi y
+// Bolt (1982). Retorna también el número de sismos usados.
+func BValue(magnitudes []float64, mc float64) (float64, float64, int, bool) {
+	var above []float64
+	sum := 0.0
+	for _, m := range magnitudes {
+		// Tolerancia para magnitudes en el borde del intervalo
+		if m >= mc-1e-9 {
+			above = append(above, m)
+			sum += m
+		}
+	}
+
+	n := len(above)
+	if n < minBValueEvents {
+		return 0, 0, n, false
+	}
+
+	mean := sum / float64(n)
+	denominator := mean - (mc - fmdBinWidth/2)
+	if denominator <= 0 {
+		return 0, 0, n, false
+	}
+	b := math.Log10(math.E) / denominator
+
+	squares := 0.0
+	for _, m := range above {
+		squares += (m - mean) * (m - mean)
+	}
+	sigma := 2.3 * b * b * math.Sqrt(squares/float64(n*(n-1)))
+
+	return b, sigma, n, true
+}
@@ -0,0 +1,156 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// repeat retorna la magnitud m repetida n veces
+func repeat(m float64, n int) []float64 {
+	magnitudes := make([]float64, n)
+	for i := range magnitudes {
+		magnitudes[i] = m
+	}
+	return magnitudes
+}
+
+// gutenbergRichter genera n magnitudes >= mc con valor b dado, redondeadas a 0.1,
+// usando los cuantiles de la distribución exponencial (muestra determinista)
+func gutenbergRichter(n int, mc, b float64) []float64 {
+	beta := b * math.Ln10
+	magnitudes := make([]float64, n)
+	for i := range magnitudes {
+		u := (float64(i) + 0.5) / float64(n)
+		m := mc - fmdBinWidth/2 - math.Log(u)/beta
+		magnitudes[i] = math.Round(m*10) / 10
+	}
+	return magnitudes
+}
+
+func TestMagnitudeOfCompleteness(t *testing.T) {
+	tests := []struct {
+		name       string
+		magnitudes []float64
+		mc         float64
+		ok         bool
+	}{
+		{"moda en 2.0", concat(repeat(1.8, 3), repeat(1.9, 5), repeat(2.0, 10), repeat(2.1, 7), repeat(2.5, 2)), 2.2, true},
+		{"empate: el intervalo menor", concat(repeat(3.0, 4), repeat(3.4, 4)), 3.2, true},
+		{"redondeo al intervalo", []float64{4.04, 4.06, 4.14}, 4.3, true},
+		{"un sismo", []float64{5}, 0, false},
+		{"vacío", nil, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc, ok := MagnitudeOfCompleteness(tt.magnitudes)
+			if ok != tt.ok || math.Abs(mc-tt.mc) > 1e-9 {
+				t.Errorf("MagnitudeOfCompleteness() = %v, %v, want %v, %v", mc, ok, tt.mc, tt.ok)
+			}
+		})
+	}
+}
+
+func TestBValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		magnitudes []float64
+		mc         float64
+		b          float64 // Valor esperado (±0.05)
+		n          int
+		ok         bool
+	}{
+		{"b=1", gutenbergRichter(2000, 3.0, 1.0), 3.0, 1.0, 2000, true},
+		{"b=1.5", gutenbergRichter(2000, 2.0, 1.5), 2.0, 1.5, 2000, true},
+		{"omite bajo Mc", concat(repeat(1.0, 500), gutenbergRichter(2000, 3.0, 1.0)), 3.0, 1.0, 2000, true},
+		{"menos del mínimo", gutenbergRichter(minBValueEvents-1, 3.0, 1.0), 3.0, 0, minBValueEvents - 1, false},
+		{"mínimo de sismos", gutenbergRichter(minBValueEvents, 3.0, 1.0), 3.0, 1.0, minBValueEvents, true},
+		{"todas en el borde inferior", repeat(3.0, 50), 3.1, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, sigma, n, ok := BValue(tt.magnitudes, tt.mc)
+			if ok != tt.ok || n != tt.n {
+				t.Fatalf("BValue() = ok %v, n %d, want ok %v, n %d", ok, n, tt.ok, tt.n)
+			}
+			if !ok {
+				return
+			}
+			tolerance := 0.05
+			if tt.n < 100 {
+				tolerance = 0.5
+			}
+			if math.Abs(b-tt.b) > tolerance {
+				t.Errorf("BValue() b = %.3f, want %.2f ± %.2f", b, tt.b, tolerance)
+			}
+			if sigma <= 0 || sigma > b {
+				t.Errorf("BValue() sigma = %.3f, want 0 < sigma < b", sigma)
+			}
+		})
+	}
+}
+
+func TestRegionStatsInsufficientEvents(t *testing.T) {
+	stats := regionStats(concat(repeat(3.0, 10), repeat(3.5, 5)))
+	if stats.Mc == nil || stats.BValue != nil {
+		t.Fatalf("regionStats() = Mc %v, b %v, want Mc without b-value", stats.Mc, stats.BValue)
+	}
+	if stats.BValueEvents != 5 || stats.Note == "" {
+		t.Errorf("regionStats() = %d events, note %q, want 5 events and a note", stats.BValueEvents, stats.Note)
+	}
+}
+
+func TestCumulativeDays(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	days := map[string]*DaySummary{
+		"2024-01-02": {Date: "2024-01-02", Count: 1, Moment: 10, Energy: 1},
+		"2024-01-04": {Date: "2024-01-04", Count: 2, Moment: 5, Energy: 2},
+	}
+
+	tests := []struct {
+		name   string
+		start  time.Time
+		end    time.Time
+		dates  []string
+		moment []float64 // Momento acumulado por día
+	}{
+		{"rango completo", start, start.AddDate(0, 0, 5),
+			[]string{"2024-01-01", "2024-01-02", "2024-01-03", "2024-01-04", "2024-01-05"},
+			[]float64{0, 10, 10, 15, 15}},
+		{"fin exclusivo a medianoche", start, start.AddDate(0, 0, 4),
+			[]string{"2024-01-01", "2024-01-02", "2024-01-03", "2024-01-04"},
+			[]float64{0, 10, 10, 15}},
+		{"sin inicio: desde el primer sismo", time.Time{}, start.AddDate(0, 0, 4).Add(time.Hour),
+			[]string{"2024-01-02", "2024-01-03", "2024-01-04", "2024-01-05"},
+			[]float64{10, 10, 15, 15}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cumulativeDays(days, tt.start, tt.end)
+			if len(got) != len(tt.dates) {
+				t.Fatalf("cumulativeDays() = %d days, want %d", len(got), len(tt.dates))
+			}
+			for i, day := range got {
+				if day.Date != tt.dates[i] || day.CumulativeMoment != tt.moment[i] {
+					t.Errorf("day %d = %s (%v), want %s (%v)", i, day.Date, day.CumulativeMoment, tt.dates[i], tt.moment[i])
+				}
+			}
+		})
+	}
+
+	if got := cumulativeDays(map[string]*DaySummary{}, start, start.AddDate(0, 0, 5)); len(got) != 0 {
+		t.Errorf("cumulativeDays() without earthquakes = %d days, want 0", len(got))
+	}
+}
+
+// concat une listas de magnitudes
+func concat(lists ...[]float64) []float64 {
+	var result []float64
+	for _, list := range lists {
+		result = append(result, list...)
+	}
+	return result
+}
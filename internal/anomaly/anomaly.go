@@ -0,0 +1,334 @@
+// Package anomaly detecta enjambres y cambios en la tasa de sismicidad
+//
+// Para cada región (océano y región de la categorización) y cada zona costera se
+// compara el número de sismos de la ventana reciente con el esperado según la tasa
+// de fondo de las semanas anteriores. Si la probabilidad de observar al menos ese
+// número con una distribución de Poisson es menor que el umbral, la zona está en
+// anomalía. Cada anomalía se notifica una vez, al empezar.
+package anomaly
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// maxEnded es el número de anomalías terminadas que se conservan
+const maxEnded = 100
+
+// Tipos de zona evaluados
+const (
+	KindRegion      = "region"       // Océano y región (ej: "Pacifico local")
+	KindCoastalZone = "coastal_zone" // Franja costera o zona de costa configurada
+)
+
+// Config son los parámetros del detector
+type Config struct {
+	Window         time.Duration // Ventana reciente
+	Background     time.Duration // Periodo de fondo antes de la ventana reciente
+	MinHistory     time.Duration // Historia mínima para evaluar (evita falsas alarmas al arrancar)
+	MinEvents      int           // Sismos mínimos en la ventana reciente
+	MaxProbability float64       // Umbral de la prueba de Poisson
+	CoastalBandKm  float64       // Ancho de la franja costera por línea de costa
+}
+
+// DefaultConfig son los parámetros por defecto
+var DefaultConfig = Config{
+	Window:         24 * time.Hour,
+	Background:     30 * 24 * time.Hour,
+	MinHistory:     7 * 24 * time.Hour,
+	MinEvents:      5,
+	MaxProbability: 0.001,
+	CoastalBandKm:  200,
+}
+
+// Anomaly es una zona con una tasa de sismicidad anómala
+type Anomaly struct {
+	Key          string    `json:"key"`
+	Kind         string    `json:"kind"`
+	Name         string    `json:"name"`
+	Observed     int       `json:"observed"`    // Sismos en la ventana reciente
+	Expected     float64   `json:"expected"`    // Sismos esperados según la tasa de fondo
+	Ratio        float64   `json:"ratio"`       // Observed / Expected
+	Probability  float64   `json:"probability"` // P(X >= Observed) con Poisson(Expected)
+	WindowHours  float64   `json:"windowHours"`
+	MaxMagnitude float64   `json:"maxMagnitude"`
+	EventIDs     []string  `json:"eventIds"` // Sismos de la ventana reciente
+	DetectedAt   time.Time `json:"-"`
+	UpdatedAt    time.Time `json:"-"`
+}
+
+// MarshalJSON formatea los tiempos igual que el de los sismos
+func (a Anomaly) MarshalJSON() ([]byte, error) {
+	type Alias Anomaly
+	return json.Marshal(&struct {
+		DetectedAt string `json:"detectedAt"`
+		UpdatedAt  string `json:"updatedAt"`
+		*Alias
+	}{
+		DetectedAt: a.DetectedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  a.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Alias:      (*Alias)(&a),
+	})
+}
+
+// zone agrupa los sismos de una zona para la evaluación
+type zone struct {
+	kind       string
+	name       string
+	recent     []models.Earthquake
+	background int
+}
+
+// Evaluate retorna las zonas en anomalía para los sismos dados (cualquier orden)
+// Los sismos más antiguos que Window + Background se ignoran. Cada evento debe
+// venir una sola vez: los reportes de varias fuentes inflarían la tasa reciente.
+func Evaluate(earthquakes []models.Earthquake, now time.Time, cfg Config) []Anomaly {
+	if len(earthquakes) == 0 {
+		return nil
+	}
+
+	// Historia disponible para la tasa de fondo
+	earliest := now
+	for _, eq := range earthquakes {
+		if eq.Time.Before(earliest) {
+			earliest = eq.Time
+		}
+	}
+	windowStart := now.Add(-cfg.Window)
+	background := windowStart.Sub(earliest)
+	if background > cfg.Background {
+		background = cfg.Background
+	}
+	if now.Sub(earliest) < cfg.MinHistory || background <= 0 {
+		return nil
+	}
+	backgroundStart := windowStart.Add(-background)
+
+	zones := make(map[string]*zone)
+	for _, eq := range earthquakes {
+		if eq.Time.Before(backgroundStart) || eq.Time.After(now) {
+			continue
+		}
+		recent := !eq.Time.Before(windowStart)
+		for _, z := range zonesOf(eq, cfg) {
+			entry, exists := zones[z.key]
+			if !exists {
+				entry = &zone{kind: z.kind, name: z.name}
+				zones[z.key] = entry
+			}
+			if recent {
+				entry.recent = append(entry.recent, eq)
+			} else {
+				entry.background++
+			}
+		}
+	}
+
+	var anomalies []Anomaly
+	for key, z := range zones {
+		observed := len(z.recent)
+		if observed < cfg.MinEvents {
+			continue
+		}
+
+		// Medio sismo de fondo como mínimo, para que una zona sin historia no tenga tasa cero
+		backgroundCount := math.Max(float64(z.background), 0.5)
+		expected := backgroundCount * cfg.Window.Hours() / background.Hours()
+		probability := PoissonTail(observed, expected)
+		if probability >= cfg.MaxProbability {
+			continue
+		}
+
+		anomaly := Anomaly{
+			Key:         key,
+			Kind:        z.kind,
+			Name:        z.name,
+			Observed:    observed,
+			Expected:    expected,
+			Ratio:       float64(observed) / expected,
+			Probability: probability,
+			WindowHours: cfg.Window.Hours(),
+			EventIDs:    make([]string, 0, observed),
+		}
+		sort.Slice(z.recent, func(i, j int) bool {
+			return z.recent[i].Time.Before(z.recent[j].Time)
+		})
+		for _, eq := range z.recent {
+			anomaly.EventIDs = append(anomaly.EventIDs, eq.ID)
+			anomaly.MaxMagnitude = math.Max(anomaly.MaxMagnitude, eq.Magnitude)
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		return anomalies[i].Key < anomalies[j].Key
+	})
+	return anomalies
+}
+
+// zoneKey identifica una zona de un sismo
+type zoneKey struct {
+	key  string
+	kind string
+	name string
+}
+
+// zonesOf retorna las zonas a las que pertenece un sismo
+func zonesOf(eq models.Earthquake, cfg Config) []zoneKey {
+	var keys []zoneKey
+	if eq.IsCategorized() {
+		name := fmt.Sprintf("%s %s", eq.Oceano, eq.OceanoRegion)
+		keys = append(keys, zoneKey{key: KindRegion + ":" + name, kind: KindRegion, name: name})
+	}
+
+	// Franja costera de la línea de costa más cercana
+	if eq.CoastSegment != "" && eq.CoastDistanceKm != nil && *eq.CoastDistanceKm <= cfg.CoastalBandKm {
+		keys = append(keys, zoneKey{key: KindCoastalZone + ":" + eq.CoastSegment, kind: KindCoastalZone, name: eq.CoastSegment})
+	}
+
+	// Zonas de costa configuradas en el archivo de regiones
+	point := models.Point{Lat: eq.Latitude, Lon: eq.Longitude}
+	for _, coastZone := range geometry.CoastZones() {
+		d, ok := geometry.DistanceToCoastline(point, coastZone.Coastline)
+		if ok && d <= coastZone.MaxDistanceKm {
+			keys = append(keys, zoneKey{key: KindCoastalZone + ":" + coastZone.Name, kind: KindCoastalZone, name: coastZone.Name})
+		}
+	}
+	return keys
+}
+
+// PoissonTail retorna P(X >= k) para X ~ Poisson(lambda)
+func PoissonTail(k int, lambda float64) float64 {
+	if k <= 0 {
+		return 1
+	}
+	if lambda <= 0 {
+		return 0
+	}
+
+	// 1 - P(X < k), sumando en escala logarítmica
+	cdf := 0.0
+	logLambda := math.Log(lambda)
+	for i := 0; i < k; i++ {
+		logFactorial, _ := math.Lgamma(float64(i + 1))
+		cdf += math.Exp(-lambda + float64(i)*logLambda - logFactorial)
+	}
+	return math.Max(0, 1-cdf)
+}
+
+// Detector mantiene las anomalías activas y notifica las nuevas
+type Detector struct {
+	mu     sync.RWMutex
+	cfg    Config
+	active map[string]Anomaly
+	recent []Anomaly // Anomalías terminadas, la más reciente al final
+	notify func(Anomaly)
+}
+
+// NewDetector crea un detector con la configuración dada
+func NewDetector(cfg Config) *Detector {
+	return &Detector{
+		cfg:    cfg,
+		active: make(map[string]Anomaly),
+	}
+}
+
+// Config retorna la configuración del detector
+func (d *Detector) Config() Config {
+	return d.cfg
+}
+
+// SetNotifier define la función llamada cuando empieza una anomalía
+func (d *Detector) SetNotifier(notify func(Anomaly)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.notify = notify
+}
+
+// Update evalúa los sismos y actualiza las anomalías activas
+// Retorna las anomalías que empezaron en esta evaluación.
+func (d *Detector) Update(earthquakes []models.Earthquake, now time.Time) []Anomaly {
+	current := Evaluate(earthquakes, now, d.cfg)
+
+	d.mu.Lock()
+	var started []Anomaly
+	seen := make(map[string]bool, len(current))
+	for _, anomaly := range current {
+		seen[anomaly.Key] = true
+		anomaly.UpdatedAt = now
+		if previous, exists := d.active[anomaly.Key]; exists {
+			anomaly.DetectedAt = previous.DetectedAt
+		} else {
+			anomaly.DetectedAt = now
+			started = append(started, anomaly)
+		}
+		d.active[anomaly.Key] = anomaly
+	}
+	for key, anomaly := range d.active {
+		if !seen[key] {
+			delete(d.active, key)
+			d.recent = append(d.recent, anomaly)
+			log.Printf("📉 Fin de anomalía de sismicidad: %s", anomaly.Name)
+		}
+	}
+	if len(d.recent) > maxEnded {
+		d.recent = d.recent[len(d.recent)-maxEnded:]
+	}
+	notify := d.notify
+	d.mu.Unlock()
+
+	for _, anomaly := range started {
+		log.Printf("📈 Anomalía de sismicidad: %s, %d sismos en %.0fh (esperados %.1f, p=%.2g)",
+			anomaly.Name, anomaly.Observed, anomaly.WindowHours, anomaly.Expected, anomaly.Probability)
+		if notify != nil {
+			notify(anomaly)
+		}
+	}
+	return started
+}
+
+// Start evalúa periódicamente los sismos retornados por earthquakes
+// La ventana reciente se desplaza con el tiempo, así que se evalúa aunque no lleguen sismos.
+func (d *Detector) Start(earthquakes func() []models.Earthquake, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			d.Update(earthquakes(), time.Now())
+		}
+	}()
+}
+
+// Active retorna las anomalías activas ordenadas por clave
+func (d *Detector) Active() []Anomaly {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	active := make([]Anomaly, 0, len(d.active))
+	for _, anomaly := range d.active {
+		active = append(active, anomaly)
+	}
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].Key < active[j].Key
+	})
+	return active
+}
+
+// Ended retorna las anomalías terminadas (la más reciente primero)
+func (d *Detector) Ended() []Anomaly {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ended := make([]Anomaly, 0, len(d.recent))
+	for i := len(d.recent) - 1; i >= 0; i-- {
+		ended = append(ended, d.recent[i])
+	}
+	return ended
+}
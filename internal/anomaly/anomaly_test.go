@@ -0,0 +1,139 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+func TestPoissonTail(t *testing.T) {
+	tests := []struct {
+		name   string
+		k      int
+		lambda float64
+		want   float64
+	}{
+		{"k = 0", 0, 2.5, 1},
+		{"k negativo", -3, 2.5, 1},
+		{"lambda = 0", 1, 0, 0},
+		{"k = 1, lambda = 1", 1, 1, 1 - math.Exp(-1)},
+		{"k = 1, lambda = 0.01", 1, 0.01, 1 - math.Exp(-0.01)},
+		{"k = 2, lambda = 3", 2, 3, 1 - math.Exp(-3)*(1+3)},
+		{"k = 3, lambda = 2", 3, 2, 1 - math.Exp(-2)*(1+2+2)},
+		{"cola lejana", 100, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PoissonTail(tt.k, tt.lambda)
+			if math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("PoissonTail(%d, %v) = %v, want %v", tt.k, tt.lambda, got, tt.want)
+			}
+		})
+	}
+}
+
+// testConfig es una configuración sin franja costera
+var testConfig = Config{
+	Window:         24 * time.Hour,
+	Background:     30 * 24 * time.Hour,
+	MinHistory:     7 * 24 * time.Hour,
+	MinEvents:      5,
+	MaxProbability: 0.001,
+}
+
+// events crea n sismos en la región dada, repartidos entre from y to antes de now
+func events(prefix, region string, n int, now time.Time, from, to time.Duration) []models.Earthquake {
+	earthquakes := make([]models.Earthquake, n)
+	for i := range earthquakes {
+		age := from + (to-from)*time.Duration(i)/time.Duration(n)
+		earthquakes[i] = models.Earthquake{
+			ID:           fmt.Sprintf("%s%d", prefix, i),
+			Magnitude:    4,
+			Oceano:       "Pacifico",
+			OceanoRegion: region,
+			Time:         now.Add(-age),
+		}
+	}
+	return earthquakes
+}
+
+func TestEvaluate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	// Un sismo de otra región hace 31 días: da 30 días de historia
+	history := events("old", "lejano", 1, now, 31*day, 31*day)
+
+	tests := []struct {
+		name        string
+		earthquakes []models.Earthquake
+		expected    float64 // Sismos esperados en la anomalía, si la hay
+		anomaly     bool
+	}{
+		{
+			name:        "enjambre sin fondo: mínimo de medio sismo",
+			earthquakes: append(events("swarm", "local", 5, now, time.Hour, 20*time.Hour), history...),
+			expected:    0.5 / 30,
+			anomaly:     true,
+		},
+		{
+			name: "enjambre sobre una tasa baja",
+			earthquakes: append(append(events("swarm", "local", 8, now, time.Hour, 20*time.Hour),
+				events("bg", "local", 3, now, 2*day, 29*day)...), history...),
+			expected: 3.0 / 30,
+			anomaly:  true,
+		},
+		{
+			name:        "menos de MinEvents",
+			earthquakes: append(events("swarm", "local", 4, now, time.Hour, 20*time.Hour), history...),
+		},
+		{
+			name: "tasa normal",
+			earthquakes: append(events("swarm", "local", 5, now, time.Hour, 20*time.Hour),
+				events("bg", "local", 150, now, 2*day, 30*day)...),
+		},
+		{
+			name: "historia menor que MinHistory",
+			earthquakes: append(events("swarm", "local", 20, now, time.Hour, 20*time.Hour),
+				events("bg", "lejano", 1, now, 6*day, 6*day)...),
+		},
+		{
+			name: "fondo anterior al periodo ignorado",
+			earthquakes: append(append(events("swarm", "local", 5, now, time.Hour, 20*time.Hour),
+				events("ancient", "local", 200, now, 40*day, 60*day)...), history...),
+			expected: 0.5 / 30,
+			anomaly:  true,
+		},
+		{
+			name: "sin sismos",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			anomalies := Evaluate(tt.earthquakes, now, testConfig)
+			if !tt.anomaly {
+				if len(anomalies) != 0 {
+					t.Errorf("Evaluate() = %d anomalies, want none", len(anomalies))
+				}
+				return
+			}
+			if len(anomalies) != 1 {
+				t.Fatalf("Evaluate() = %d anomalies, want 1", len(anomalies))
+			}
+			got := anomalies[0]
+			if got.Key != KindRegion+":Pacifico local" || got.Kind != KindRegion {
+				t.Errorf("anomaly key = %s (%s), want region Pacifico local", got.Key, got.Kind)
+			}
+			if math.Abs(got.Expected-tt.expected) > 1e-9 {
+				t.Errorf("Expected = %v, want %v", got.Expected, tt.expected)
+			}
+			if got.Observed != len(got.EventIDs) || got.Probability >= testConfig.MaxProbability {
+				t.Errorf("anomaly = %d observed, %d ids, p=%v", got.Observed, len(got.EventIDs), got.Probability)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/andresgallo/evida_backend_go/internal/alerts"
+)
+
+// handleAlertRules lista (GET) y crea (POST) reglas de alerta
+func (s *Server) handleAlertRules(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.alerts.Rules())
+	case http.MethodPost:
+		if !s.authorizeAdmin(w, r) {
+			return
+		}
+		rule, ok := decodeRule(w, r)
+		if !ok {
+			return
+		}
+		if err := s.alerts.AddRule(rule); err != nil {
+			writeRuleError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, rule)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleAlertRule reemplaza (PUT) o elimina (DELETE) la regla /api/alert-rules/{id}
+func (s *Server) handleAlertRule(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/alert-rules/")
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		for _, rule := range s.alerts.Rules() {
+			if rule.ID == id {
+				writeJSON(w, http.StatusOK, rule)
+				return
+			}
+		}
+		http.Error(w, alerts.ErrRuleNotFound.Error(), http.StatusNotFound)
+	case http.MethodPut:
+		if !s.authorizeAdmin(w, r) {
+			return
+		}
+		rule, ok := decodeRule(w, r)
+		if !ok {
+			return
+		}
+		if rule.ID == "" {
+			rule.ID = id
+		}
+		if rule.ID != id {
+			http.Error(w, "rule id does not match the URL", http.StatusBadRequest)
+			return
+		}
+		if err := s.alerts.UpdateRule(rule); err != nil {
+			writeRuleError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, rule)
+	case http.MethodDelete:
+		if !s.authorizeAdmin(w, r) {
+			return
+		}
+		if err := s.alerts.DeleteRule(id); err != nil {
+			writeRuleError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleGetAlerts retorna las alertas recientes (la más reciente primero)
+func (s *Server) handleGetAlerts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, err := parseOptionalInt(r.URL.Query().Get("limit"), "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	setCORSHeaders(w)
+	writeJSON(w, http.StatusOK, s.alerts.Alerts(limit))
+}
+
+// authorizeAdmin verifica el token de administrador
+// Sin token configurado las reglas no se pueden modificar por la API.
+func (s *Server) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if s.adminToken == "" {
+		http.Error(w, "Alert rule changes are disabled: admin token not configured", http.StatusForbidden)
+		return false
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// decodeRule lee una regla del cuerpo de la petición
+func decodeRule(w http.ResponseWriter, r *http.Request) (alerts.Rule, bool) {
+	var rule alerts.Rule
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rule); err != nil {
+		http.Error(w, "Invalid rule: "+err.Error(), http.StatusBadRequest)
+		return rule, false
+	}
+	return rule, true
+}
+
+// writeRuleError traduce los errores del motor de alertas a códigos HTTP
+func writeRuleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, alerts.ErrRuleNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, alerts.ErrRuleExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		var saveErr *alerts.SaveError
+		if errors.As(err, &saveErr) {
+			log.Printf("Error saving alert rules: %v", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+// setCORSHeaders permite las peticiones desde cualquier origen, incluidas las que modifican datos
+func setCORSHeaders(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+}
+
+// writeJSON escribe una respuesta JSON con el código dado
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// defaultChangesLimit es el número de cambios por respuesta si no se indica limit
+const defaultChangesLimit = 1000
+
+// handleGetChanges retorna las creaciones, actualizaciones y eliminaciones posteriores a since
+// Acepta since (secuencia del último cambio aplicado) y limit. El cliente empieza desde
+// el header X-Change-Seq de /api/earthquakes: sin since (o con 0) retorna reset y el cursor actual.
+func (s *Server) handleGetChanges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	var since uint64
+	if value := r.URL.Query().Get("since"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid since: expected a change sequence number", http.StatusBadRequest)
+			return
+		}
+		since = parsed
+	}
+
+	limit, err := parseOptionalInt(r.URL.Query().Get("limit"), "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if limit == 0 {
+		limit = defaultChangesLimit
+	}
+
+	writeJSON(w, http.StatusOK, s.manager.GetChanges(since, limit))
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/andresgallo/evida_backend_go/internal/manager"
+)
+
+// earthquakeDetailResponse es la respuesta de /api/earthquakes/{id}
+type earthquakeDetailResponse struct {
+	manager.EarthquakeDetail
+	Links map[string]string `json:"links"`
+}
+
+// handleGetEarthquake retorna el detalle del sismo /api/earthquakes/{id}: orígenes de
+// todas las fuentes, revisiones, explicación de la categoría, poblaciones cercanas y enlaces
+func (s *Server) handleGetEarthquake(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/earthquakes/")
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	detail, ok := s.manager.GetDetail(id)
+	if !ok {
+		http.Error(w, "earthquake not found", http.StatusNotFound)
+		return
+	}
+
+	links := map[string]string{
+		"self": "/api/earthquakes/" + url.PathEscape(id),
+	}
+	if detail.Earthquake.URL != "" {
+		links["source"] = detail.Earthquake.URL
+	}
+	if detail.Sequence != nil {
+		links["sequences"] = "/api/sequences"
+	}
+
+	writeJSON(w, http.StatusOK, earthquakeDetailResponse{
+		EarthquakeDetail: detail,
+		Links:            links,
+	})
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/andresgallo/evida_backend_go/internal/export"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// Formatos de salida de /api/earthquakes
+const (
+	formatJSON    = "json"
+	formatGeoJSON = "geojson"
+	formatCSV     = "csv"
+	formatKML     = "kml"
+	formatQuakeML = "quakeml"
+)
+
+// Título y nombre de archivo de las exportaciones
+const (
+	exportTitle    = "EVIDA - Sismos"
+	exportFilename = "evida-sismos"
+)
+
+// responseFormat elige el formato con el parámetro format o, si no viene, con el header Accept
+func responseFormat(r *http.Request) (string, error) {
+	if value := strings.ToLower(r.URL.Query().Get("format")); value != "" {
+		switch value {
+		case formatJSON, formatGeoJSON, formatCSV, formatKML, formatQuakeML:
+			return value, nil
+		}
+		return "", fmt.Errorf("invalid format %q: expected json, geojson, csv, kml or quakeml", value)
+	}
+
+	accept := r.Header.Get("Accept")
+	switch {
+	case strings.Contains(accept, export.GeoJSONContentType):
+		return formatGeoJSON, nil
+	case strings.Contains(accept, "text/csv"):
+		return formatCSV, nil
+	case strings.Contains(accept, export.KMLContentType):
+		return formatKML, nil
+	}
+	return formatJSON, nil
+}
+
+// writeExport escribe los sismos en un formato de exportación
+// Los formatos de archivo (CSV, KML y QuakeML) se descargan como adjuntos.
+func writeExport(w http.ResponseWriter, format string, earthquakes []models.Earthquake) {
+	var err error
+	switch format {
+	case formatGeoJSON:
+		w.Header().Set("Content-Type", export.GeoJSONContentType)
+		err = export.WriteGeoJSON(w, earthquakes, exportTitle)
+	case formatCSV:
+		setAttachment(w, export.CSVContentType, "csv")
+		err = export.WriteCSV(w, earthquakes)
+	case formatKML:
+		setAttachment(w, export.KMLContentType, "kml")
+		err = export.WriteKML(w, earthquakes, exportTitle)
+	case formatQuakeML:
+		setAttachment(w, export.QuakeMLContentType, "xml")
+		err = export.WriteQuakeML(w, earthquakes)
+	}
+
+	if err != nil {
+		log.Printf("Error encoding %s export: %v", format, err)
+	}
+}
+
+// setAttachment fija el tipo de contenido y el nombre del archivo descargado
+func setAttachment(w http.ResponseWriter, contentType, extension string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", exportFilename+"."+extension))
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Métricas de las solicitudes HTTP por ruta
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "evida_http_requests_total",
+		Help: "Solicitudes HTTP atendidas por ruta, método y código de estado.",
+	}, []string{"route", "method", "code"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "evida_http_request_duration_seconds",
+		Help:    "Latencia de las solicitudes HTTP por ruta y método.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+)
+
+// streamingRoutes son las rutas de conexiones de larga duración: se cuentan
+// pero su duración no se registra como latencia
+var streamingRoutes = map[string]bool{
+	"/ws":         true,
+	"/api/stream": true,
+}
+
+// Instrument envuelve el mux para medir cada solicitud según la ruta registrada
+// que la atiende (las rutas no registradas se agrupan como "unmatched")
+func Instrument(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		started := time.Now()
+		mux.ServeHTTP(recorder, r)
+
+		httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Inc()
+		if !streamingRoutes[route] {
+			httpDuration.WithLabelValues(route, r.Method).Observe(time.Since(started).Seconds())
+		}
+	})
+}
+
+// statusRecorder guarda el código de estado de la respuesta
+// Implementa Hijacker y Flusher para no romper WebSocket ni las respuestas por partes.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap permite a http.ResponseController llegar al ResponseWriter original
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response does not support hijacking")
+	}
+	r.status = http.StatusSwitchingProtocols
+	r.wroteHeader = true
+	return hijacker.Hijack()
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/manager"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+	"github.com/andresgallo/evida_backend_go/internal/store"
+)
+
+// Formatos aceptados para start y end
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// parseQuery construye una consulta a partir de los parámetros de la URL
+func parseQuery(r *http.Request) (manager.Query, error) {
+	values := r.URL.Query()
+	q := manager.Query{
+		Oceano:               values.Get("oceano"),
+		Region:               values.Get("region"),
+		IncludeUncategorized: includes(r, "uncategorized"),
+		OrderBy:              values.Get("orderby"),
+		Cursor:               values.Get("cursor"),
+	}
+
+	var err error
+	if q.MinMagnitude, err = parseOptionalFloat(values.Get("minmag"), "minmag"); err != nil {
+		return q, err
+	}
+	if q.MaxMagnitude, err = parseOptionalFloat(values.Get("maxmag"), "maxmag"); err != nil {
+		return q, err
+	}
+	if q.MinDepth, err = parseOptionalFloat(values.Get("mindepth"), "mindepth"); err != nil {
+		return q, err
+	}
+	if q.MaxDepth, err = parseOptionalFloat(values.Get("maxdepth"), "maxdepth"); err != nil {
+		return q, err
+	}
+	if q.Start, err = parseOptionalTime(values.Get("start"), "start"); err != nil {
+		return q, err
+	}
+	if q.End, err = parseOptionalTime(values.Get("end"), "end"); err != nil {
+		return q, err
+	}
+	if !q.Start.IsZero() && !q.End.IsZero() && !q.Start.Before(q.End) {
+		return q, fmt.Errorf("start must be before end")
+	}
+
+	if value := values.Get("source"); value != "" {
+		for _, source := range strings.Split(value, ",") {
+			if source = strings.TrimSpace(source); source != "" {
+				q.Sources = append(q.Sources, source)
+			}
+		}
+	}
+
+	if value := values.Get("bbox"); value != "" {
+		box, err := parseBoundingBox(value)
+		if err != nil {
+			return q, err
+		}
+		q.BoundingBox = &box
+	}
+
+	if err := parseRadius(values.Get("lat"), values.Get("lon"), values.Get("maxradiuskm"), &q); err != nil {
+		return q, err
+	}
+
+	if q.Limit, err = parseOptionalInt(values.Get("limit"), "limit"); err != nil {
+		return q, err
+	}
+	if q.Offset, err = parseOptionalInt(values.Get("offset"), "offset"); err != nil {
+		return q, err
+	}
+
+	return q, nil
+}
+
+// parseRadius completa el centro y el radio de la búsqueda por radio
+// lat y lon van juntos; maxradiuskm requiere ambos
+func parseRadius(lat, lon, radius string, q *manager.Query) error {
+	if lat == "" && lon == "" {
+		if radius != "" {
+			return fmt.Errorf("maxradiuskm requires lat and lon")
+		}
+		if q.OrderBy == manager.OrderByDistance {
+			return fmt.Errorf("orderby=distance requires lat and lon")
+		}
+		return nil
+	}
+
+	latValue, err := strconv.ParseFloat(lat, 64)
+	if err != nil || latValue < -90 || latValue > 90 {
+		return fmt.Errorf("invalid lat")
+	}
+	lonValue, err := strconv.ParseFloat(lon, 64)
+	if err != nil || lonValue < -180 || lonValue > 180 {
+		return fmt.Errorf("invalid lon")
+	}
+	q.Center = &models.Point{Lat: latValue, Lon: lonValue}
+
+	if radius != "" {
+		radiusValue, err := strconv.ParseFloat(radius, 64)
+		if err != nil || radiusValue <= 0 {
+			return fmt.Errorf("invalid maxradiuskm")
+		}
+		q.MaxRadiusKm = radiusValue
+	}
+	return nil
+}
+
+// parseOptionalFloat convierte un parámetro numérico; vacío retorna nil
+func parseOptionalFloat(value, name string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+	return &parsed, nil
+}
+
+// parseOptionalInt convierte un parámetro entero no negativo; vacío retorna 0
+func parseOptionalInt(value, name string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return parsed, nil
+}
+
+// parseOptionalTime convierte una fecha ISO 8601; sin zona horaria se asume UTC
+func parseOptionalTime(value, name string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid %s", name)
+}
+
+// parseBoundingBox convierte bbox=minLon,minLat,maxLon,maxLat (orden GeoJSON)
+func parseBoundingBox(value string) (store.BoundingBox, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return store.BoundingBox{}, fmt.Errorf("invalid bbox: expected minLon,minLat,maxLon,maxLat")
+	}
+
+	coords := make([]float64, 4)
+	for i, part := range parts {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return store.BoundingBox{}, fmt.Errorf("invalid bbox")
+		}
+		coords[i] = parsed
+	}
+
+	box := store.BoundingBox{MinLon: coords[0], MinLat: coords[1], MaxLon: coords[2], MaxLat: coords[3]}
+	if box.MinLat > box.MaxLat || box.MinLon > box.MaxLon ||
+		box.MinLat < -90 || box.MaxLat > 90 || box.MinLon < -180 || box.MaxLon > 180 {
+		return store.BoundingBox{}, fmt.Errorf("invalid bbox")
+	}
+	return box, nil
+}
+
+// defaultAnalyticsWindow es la ventana de /api/stats/analytics si no se indica otra
+const defaultAnalyticsWindow = 7 * 24 * time.Hour
+
+// parseAnalyticsRange retorna el rango [start, end) de /api/stats/analytics
+// start/end tienen prioridad sobre window; sin end se usa la hora actual.
+func parseAnalyticsRange(r *http.Request) (time.Time, time.Time, error) {
+	values := r.URL.Query()
+
+	start, err := parseOptionalTime(values.Get("start"), "start")
+	if err != nil {
+		return start, start, err
+	}
+	end, err := parseOptionalTime(values.Get("end"), "end")
+	if err != nil {
+		return start, end, err
+	}
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	if start.IsZero() {
+		window := defaultAnalyticsWindow
+		if value := values.Get("window"); value != "" {
+			if window, err = parseWindow(value); err != nil {
+				return start, end, err
+			}
+		}
+		start = end.Add(-window)
+	}
+
+	if !start.Before(end) {
+		return start, end, fmt.Errorf("start must be before end")
+	}
+	return start, end, nil
+}
+
+// parseWindow convierte una duración de Go (24h, 90m) o en días (7d)
+func parseWindow(value string) (time.Duration, error) {
+	var window time.Duration
+	if days := strings.TrimSuffix(value, "d"); days != value {
+		parsed, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window")
+		}
+		window = time.Duration(parsed * 24 * float64(time.Hour))
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid window")
+		}
+		window = parsed
+	}
+
+	if window <= 0 {
+		return 0, fmt.Errorf("invalid window")
+	}
+	return window, nil
+}
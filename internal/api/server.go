@@ -2,12 +2,21 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/andresgallo/evida_backend_go/internal/alerts"
+	"github.com/andresgallo/evida_backend_go/internal/anomaly"
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
 	"github.com/andresgallo/evida_backend_go/internal/manager"
+	"github.com/andresgallo/evida_backend_go/internal/sequence"
+	"github.com/andresgallo/evida_backend_go/internal/stream"
 	"github.com/andresgallo/evida_backend_go/internal/websocket"
 	ws "github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var upgrader = ws.Upgrader{
@@ -21,18 +30,31 @@ var upgrader = ws.Upgrader{
 
 // Server representa el servidor HTTP/WebSocket
 type Server struct {
-	manager *manager.EarthquakeManager
-	hub     *websocket.Hub
+	manager    *manager.EarthquakeManager
+	hub        *websocket.Hub
+	alerts     *alerts.Engine
+	anomalies  *anomaly.Detector
+	stream     *stream.Broker
+	adminToken string // Token requerido para modificar reglas ("" = no se pueden modificar)
 }
 
 // NewServer crea un nuevo servidor
-func NewServer(manager *manager.EarthquakeManager, hub *websocket.Hub) *Server {
+func NewServer(manager *manager.EarthquakeManager, hub *websocket.Hub, streamBroker *stream.Broker, alertEngine *alerts.Engine, anomalyDetector *anomaly.Detector) *Server {
 	return &Server{
-		manager: manager,
-		hub:     hub,
+		manager:   manager,
+		hub:       hub,
+		stream:    streamBroker,
+		alerts:    alertEngine,
+		anomalies: anomalyDetector,
 	}
 }
 
+// SetAdminToken exige el token dado (header "Authorization: Bearer <token>")
+// para crear, modificar o eliminar reglas de alerta
+func (s *Server) SetAdminToken(token string) {
+	s.adminToken = token
+}
+
 // SetupRoutes configura las rutas del servidor
 func (s *Server) SetupRoutes() *http.ServeMux {
 	mux := http.NewServeMux()
@@ -40,10 +62,25 @@ func (s *Server) SetupRoutes() *http.ServeMux {
 	// WebSocket endpoint
 	mux.HandleFunc("/ws", s.handleWebSocket)
 
+	// Server-Sent Events (alternativa al WebSocket)
+	mux.HandleFunc("/api/stream", s.handleStream)
+
 	// API REST endpoints
 	mux.HandleFunc("/api/earthquakes", s.handleGetEarthquakes)
+	mux.HandleFunc("/api/earthquakes/", s.handleGetEarthquake)
+	mux.HandleFunc("/api/changes", s.handleGetChanges)
 	mux.HandleFunc("/api/stats", s.handleGetStats)
+	mux.HandleFunc("/api/stats/analytics", s.handleGetAnalytics)
+	mux.HandleFunc("/api/regions", s.handleGetRegions)
 	mux.HandleFunc("/api/health", s.handleHealth)
+	mux.HandleFunc("/api/sequences", s.handleGetSequences)
+	mux.HandleFunc("/api/anomalies", s.handleGetAnomalies)
+	mux.HandleFunc("/api/alerts", s.handleGetAlerts)
+	mux.HandleFunc("/api/alert-rules", s.handleAlertRules)
+	mux.HandleFunc("/api/alert-rules/", s.handleAlertRule)
+
+	// Métricas en formato de Prometheus
+	mux.Handle("/metrics", promhttp.Handler())
 
 	return mux
 }
@@ -66,30 +103,122 @@ func (s *Server) handleGetEarthquakes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Obtener parámetros de consulta
-	oceano := r.URL.Query().Get("oceano")
-	region := r.URL.Query().Get("region")
+	query, err := parseQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format, err := responseFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Se lee antes de consultar: los cambios posteriores a esta secuencia pueden
+	// estar ya en la lista, pero aplicarlos de nuevo no pierde nada
+	changeSeq := s.manager.ChangeSeq()
+
+	result, err := s.manager.Find(query)
+	if err == manager.ErrInvalidCursor || err == manager.ErrInvalidOrderBy {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// La paginación va en cabeceras para no cambiar el formato de la respuesta
+	w.Header().Set("X-Total-Count", strconv.Itoa(result.Total))
+	if result.NextCursor != "" {
+		w.Header().Set("X-Next-Cursor", result.NextCursor)
+	}
+	w.Header().Set("X-Change-Seq", strconv.FormatUint(changeSeq, 10))
+	w.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Next-Cursor, X-Change-Seq")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Vary", "Accept")
 
-	var earthquakes interface{}
-	if oceano != "" {
-		earthquakes = s.manager.GetByOceano(oceano)
-	} else if region != "" {
-		earthquakes = s.manager.GetByRegion(region)
-	} else {
-		earthquakes = s.manager.GetAll()
+	if format != formatJSON {
+		writeExport(w, format, result.Earthquakes)
+		return
 	}
 
 	// Enviar respuesta JSON
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	if err := json.NewEncoder(w).Encode(earthquakes); err != nil {
+	if err := json.NewEncoder(w).Encode(result.Earthquakes); err != nil {
 		log.Printf("Error encoding earthquakes: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
 }
 
+// handleGetSequences retorna las secuencias de sismos (la más reciente primero)
+// Acepta minmag (magnitud mínima del sismo principal), limit e include=uncategorized
+func (s *Server) handleGetSequences(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	minMagnitude, err := parseOptionalFloat(r.URL.Query().Get("minmag"), "minmag")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := parseOptionalInt(r.URL.Query().Get("limit"), "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	includeUncategorized := includes(r, "uncategorized")
+
+	sequences := make([]sequence.Sequence, 0)
+	for _, seq := range s.manager.GetSequences() {
+		if !includeUncategorized && !seq.Mainshock.IsCategorized() {
+			continue
+		}
+		if minMagnitude != nil && seq.Mainshock.Magnitude < *minMagnitude {
+			continue
+		}
+		sequences = append(sequences, seq)
+		if limit > 0 && len(sequences) == limit {
+			break
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if err := json.NewEncoder(w).Encode(sequences); err != nil {
+		log.Printf("Error encoding sequences: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleGetAnomalies retorna las anomalías de sismicidad activas y las terminadas recientemente
+func (s *Server) handleGetAnomalies(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := map[string]interface{}{
+		"active": s.anomalies.Active(),
+		"ended":  s.anomalies.Ended(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding anomalies: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
 // handleGetStats retorna estadísticas de los sismos
 func (s *Server) handleGetStats(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -99,6 +228,7 @@ func (s *Server) handleGetStats(w http.ResponseWriter, r *http.Request) {
 
 	stats := s.manager.GetStats()
 	stats["websocket_clients"] = s.hub.GetClientCount()
+	stats["stream_clients"] = s.stream.ClientCount()
 
 	w.Header().Set("Content-Type", "application/json")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -110,6 +240,76 @@ func (s *Server) handleGetStats(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleGetAnalytics retorna histogramas, momento y energía acumulados, Mc y valor b
+// El rango es window (ej: 24h, 7d; por defecto 7d) hasta ahora, o start/end
+func (s *Server) handleGetAnalytics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	start, end, err := parseAnalyticsRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report := s.manager.GetAnalytics(start, end, includes(r, "uncategorized"))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		log.Printf("Error encoding analytics: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
+// handleGetRegions retorna los polígonos de las regiones como GeoJSON
+// Acepta el parámetro opcional tolerance (en grados) para simplificar los polígonos
+func (s *Server) handleGetRegions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	tolerance := 0.0
+	if value := r.URL.Query().Get("tolerance"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 {
+			http.Error(w, "Invalid tolerance", http.StatusBadRequest)
+			return
+		}
+		tolerance = parsed
+	}
+
+	// El ETag depende de la versión del archivo y de la tolerancia pedida
+	etag := fmt.Sprintf("\"%s-%g\"", geometry.RegionDataVersion(), tolerance)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	regions, err := geometry.RegionsGeoJSON(tolerance)
+	if err != nil {
+		log.Printf("Error building regions GeoJSON: %v", err)
+		http.Error(w, "Region data not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+
+	if err := json.NewEncoder(w).Encode(regions); err != nil {
+		log.Printf("Error encoding regions: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+}
+
 // handleHealth retorna el estado del servidor
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -119,8 +319,12 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 	response := map[string]interface{}{
 		"status":            "ok",
-		"earthquake_count":  s.manager.GetCount(),
+		"earthquake_count":  s.manager.GetCount(), // Categorizados, como /api/earthquakes
+		"uncategorized":     s.manager.GetUncategorizedCount(),
 		"websocket_clients": s.hub.GetClientCount(),
+		"stream_clients":    s.stream.ClientCount(),
+		"sources":           s.manager.GetSourceHealth(),
+		"subscribers":       s.manager.Events().Stats(),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -128,3 +332,13 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(response)
 }
+
+// includes indica si el parámetro include (lista separada por comas) contiene el valor dado
+func includes(r *http.Request, value string) bool {
+	for _, item := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.EqualFold(strings.TrimSpace(item), value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/stream"
+)
+
+const (
+	// streamHeartbeat es el período de los comentarios que mantienen viva la conexión en los proxies
+	streamHeartbeat = 30 * time.Second
+
+	// streamRetry es el tiempo que el navegador espera antes de reconectarse
+	streamRetry = 5 * time.Second
+)
+
+// handleStream envía las notificaciones como Server-Sent Events
+// Acepta el header Last-Event-ID (o el parámetro lastEventId) para reanudar la conexión.
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	var lastID uint64
+	if lastEventID != "" {
+		parsed, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			http.Error(w, "Invalid Last-Event-ID", http.StatusBadRequest)
+			return
+		}
+		lastID = parsed
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// La conexión dura más que el WriteTimeout del servidor
+	controller := http.NewResponseController(w)
+	if err := controller.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		log.Printf("Error disabling stream write deadline: %v", err)
+	}
+
+	client, backlog, resync := s.stream.Subscribe(lastID, lastEventID != "")
+	defer s.stream.Unsubscribe(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+	if resync {
+		// Se perdieron mensajes que ya no están en el historial: el cliente debe volver a
+		// consultar la API y continuar desde el último ID
+		lastID := s.stream.LastID()
+		writeStreamMessage(w, stream.Message{
+			ID:   lastID,
+			Type: "resync",
+			Data: []byte(fmt.Sprintf(`{"type":"resync","data":{"lastEventId":%d}}`, lastID)),
+		})
+	}
+	for _, message := range backlog {
+		writeStreamMessage(w, message)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case message, ok := <-client.Messages():
+			if !ok {
+				// El broker desconectó al cliente por atrasarse
+				return
+			}
+			writeStreamMessage(w, message)
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// writeStreamMessage escribe un mensaje como evento SSE
+func writeStreamMessage(w http.ResponseWriter, message stream.Message) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", message.ID, message.Type, message.Data)
+}
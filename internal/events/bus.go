@@ -0,0 +1,165 @@
+// Package events implementa el bus de eventos en proceso del backend.
+// Cada consumidor (WebSocket, webhooks, motor de alertas, ...) se suscribe con
+// un nombre y su propio buffer: un consumidor lento pierde sus propios eventos
+// (y quedan contados) sin quitarle eventos a los demás.
+package events
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// Type es el tipo de cambio que describe un evento
+type Type string
+
+const (
+	Created Type = "created" // Sismo nuevo
+	Updated Type = "updated" // La fuente corrigió un sismo existente
+	Deleted Type = "deleted" // Sismo eliminado del almacenamiento
+)
+
+// ErrDuplicateSubscriber se retorna al suscribir un nombre que ya está en uso
+var ErrDuplicateSubscriber = errors.New("subscriber already exists")
+
+// Event es un cambio en el conjunto de sismos
+type Event struct {
+	Seq        uint64             `json:"seq"` // Número de secuencia del cambio (el mismo de /api/changes)
+	Type       Type               `json:"type"`
+	Earthquake models.Earthquake  `json:"earthquake"`
+	Previous   *models.Earthquake `json:"previous,omitempty"` // Versión anterior (solo Updated)
+	Time       time.Time          `json:"time"`               // Momento de la publicación
+}
+
+// Notifiable indica si el evento es un sismo nuevo que debe notificarse a los
+// usuarios: categorizado y no histórico
+func (e Event) Notifiable() bool {
+	return e.Type == Created && e.Earthquake.IsCategorized() && !e.Earthquake.Historical
+}
+
+// Bus distribuye los eventos a todos los suscriptores
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[string]*Subscription
+}
+
+// Subscription es la suscripción de un consumidor al bus
+type Subscription struct {
+	name      string
+	bus       *Bus
+	ch        chan Event
+	delivered uint64 // Atómico
+	dropped   uint64 // Atómico
+}
+
+// SubscriberStats describe el estado de un suscriptor
+type SubscriberStats struct {
+	Name      string `json:"name"`
+	Buffer    int    `json:"buffer"`
+	Queued    int    `json:"queued"`    // Eventos esperando en el buffer
+	Delivered uint64 `json:"delivered"` // Eventos entregados al buffer
+	Dropped   uint64 `json:"dropped"`   // Eventos descartados por buffer lleno
+}
+
+// NewBus crea un bus sin suscriptores
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[string]*Subscription),
+	}
+}
+
+// Subscribe registra un consumidor con un buffer de buffer eventos
+func (b *Bus) Subscribe(name string, buffer int) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.subscribers[name]; exists {
+		return nil, ErrDuplicateSubscriber
+	}
+
+	sub := &Subscription{
+		name: name,
+		bus:  b,
+		ch:   make(chan Event, buffer),
+	}
+	b.subscribers[name] = sub
+	return sub, nil
+}
+
+// Publish entrega el evento con el número de secuencia dado (lo asigna quien
+// registra el cambio) a cada suscriptor sin bloquear. Si el buffer de un
+// suscriptor está lleno el evento se descarta solo para ese suscriptor.
+func (b *Bus) Publish(seq uint64, eventType Type, eq models.Earthquake, previous *models.Earthquake) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event := Event{
+		Seq:        seq,
+		Type:       eventType,
+		Earthquake: eq,
+		Previous:   previous,
+		Time:       time.Now(),
+	}
+
+	for _, sub := range b.subscribers {
+		select {
+		case sub.ch <- event:
+			atomic.AddUint64(&sub.delivered, 1)
+		default:
+			dropped := atomic.AddUint64(&sub.dropped, 1)
+			log.Printf("⚠️  Suscriptor %s lleno: evento %d (%s %s) descartado (%d descartados)",
+				sub.name, event.Seq, event.Type, eq.ID, dropped)
+		}
+	}
+
+	return event
+}
+
+// Stats retorna el estado de los suscriptores ordenados por nombre
+func (b *Bus) Stats() []SubscriberStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := make([]SubscriberStats, 0, len(b.subscribers))
+	for _, sub := range b.subscribers {
+		stats = append(stats, SubscriberStats{
+			Name:      sub.name,
+			Buffer:    cap(sub.ch),
+			Queued:    len(sub.ch),
+			Delivered: atomic.LoadUint64(&sub.delivered),
+			Dropped:   atomic.LoadUint64(&sub.dropped),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// Name retorna el nombre del suscriptor
+func (s *Subscription) Name() string {
+	return s.name
+}
+
+// Events retorna el canal de eventos del suscriptor
+// Se cierra al llamar Close.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Close cancela la suscripción y cierra el canal de eventos
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	if s.bus.subscribers[s.name] == s {
+		delete(s.bus.subscribers, s.name)
+		close(s.ch)
+	}
+}
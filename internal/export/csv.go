@@ -0,0 +1,77 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// CSVContentType es el tipo MIME de CSV
+const CSVContentType = "text/csv; charset=utf-8"
+
+// csvColumns es el orden fijo de las columnas; solo se agregan columnas al final
+var csvColumns = []string{
+	"id", "time", "latitude", "longitude", "depth", "magnitude", "place", "source",
+	"oceano", "oceanoRegion", "threatLevel", "coastDistanceKm", "sequenceId", "revision", "updated", "url",
+}
+
+// WriteCSV escribe los sismos como CSV con encabezado; los tiempos van en ISO 8601 UTC
+func WriteCSV(w io.Writer, earthquakes []models.Earthquake) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+
+	for _, eq := range earthquakes {
+		updated := ""
+		if !eq.UpdatedAt.IsZero() {
+			updated = formatISOTime(eq.UpdatedAt)
+		}
+		coastDistance := ""
+		if eq.CoastDistanceKm != nil {
+			coastDistance = formatFloat(*eq.CoastDistanceKm)
+		}
+
+		record := []string{
+			csvText(eq.ID),
+			formatISOTime(eq.Time),
+			formatFloat(eq.Latitude),
+			formatFloat(eq.Longitude),
+			formatFloat(eq.Depth),
+			formatFloat(eq.Magnitude),
+			csvText(eq.Location),
+			csvText(eq.Source),
+			eq.Oceano,
+			eq.OceanoRegion,
+			eq.ThreatLevel,
+			coastDistance,
+			eq.SequenceID,
+			strconv.Itoa(eq.Revision),
+			updated,
+			csvText(eq.URL),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvText neutraliza un texto de las fuentes que Excel interpretaría como fórmula
+// (empieza con =, +, -, @, tabulador o retorno de carro) anteponiéndole una comilla
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// formatFloat formatea un número con la mínima cantidad de decimales necesaria
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
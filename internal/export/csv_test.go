@@ -0,0 +1,85 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+func TestWriteCSV(t *testing.T) {
+	coast := 0.0
+	bogota := time.FixedZone("COT", -5*3600)
+	earthquakes := []models.Earthquake{
+		{
+			ID:              "us7000abcd",
+			Time:            time.Date(2024, 3, 1, 7, 30, 15, 250e6, bogota),
+			Latitude:        2.5,
+			Longitude:       -79.25,
+			Depth:           10,
+			Magnitude:       6.1,
+			Location:        "Frente a Tumaco, Nariño",
+			Source:          "USGS",
+			Oceano:          "Pacifico",
+			OceanoRegion:    "local",
+			ThreatLevel:     "watch",
+			CoastDistanceKm: &coast,
+			SequenceID:      "seq-us7000abcd",
+			Revision:        2,
+			UpdatedAt:       time.Date(2024, 3, 1, 12, 45, 0, 0, time.UTC),
+			URL:             "https://earthquake.usgs.gov/earthquakes/eventpage/us7000abcd",
+		},
+		{
+			ID:           "sgc2024xyz",
+			Time:         time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			Latitude:     -1,
+			Longitude:    -75.5,
+			Depth:        120.4,
+			Magnitude:    3,
+			Location:     "=HYPERLINK(\"http://example.com\")",
+			Source:       "@SGC",
+			Oceano:       models.Uncategorized,
+			OceanoRegion: models.Uncategorized,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, earthquakes); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"id,time,latitude,longitude,depth,magnitude,place,source,oceano,oceanoRegion,threatLevel,coastDistanceKm,sequenceId,revision,updated,url",
+		`us7000abcd,2024-03-01T12:30:15.250Z,2.5,-79.25,10,6.1,"Frente a Tumaco, Nariño",USGS,Pacifico,local,watch,0,seq-us7000abcd,2,2024-03-01T12:45:00.000Z,https://earthquake.usgs.gov/earthquakes/eventpage/us7000abcd`,
+		`sgc2024xyz,2024-03-02T00:00:00.000Z,-1,-75.5,120.4,3,"'=HYPERLINK(""http://example.com"")",'@SGC,Uncategorized,Uncategorized,,,,0,,`,
+		"",
+	}, "\n")
+	if got := buf.String(); got != want {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"Tumaco", "Tumaco"},
+		{"=1+1", "'=1+1"},
+		{"+57", "'+57"},
+		{"-cmd", "'-cmd"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+	}
+
+	for _, tt := range tests {
+		if got := csvText(tt.value); got != tt.want {
+			t.Errorf("csvText(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package export convierte listas de sismos a los formatos de intercambio que piden
+// los sistemas externos (SIG, visores de mapas y herramientas sismológicas)
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// GeoJSONContentType es el tipo MIME de GeoJSON (RFC 7946)
+const GeoJSONContentType = "application/geo+json"
+
+// FeatureCollection es una colección GeoJSON de sismos al estilo de los feeds de USGS
+type FeatureCollection struct {
+	Type     string             `json:"type"`
+	Metadata Metadata           `json:"metadata"`
+	Features []geometry.Feature `json:"features"`
+}
+
+// Metadata describe la colección exportada
+type Metadata struct {
+	Generated time.Time `json:"generated"`
+	Title     string    `json:"title"`
+	Count     int       `json:"count"`
+}
+
+// GeoJSON construye una FeatureCollection con un Feature Point por sismo
+// Las coordenadas son [lon, lat, profundidad en km] como en los feeds de USGS.
+func GeoJSON(earthquakes []models.Earthquake, title string) FeatureCollection {
+	collection := FeatureCollection{
+		Type: "FeatureCollection",
+		Metadata: Metadata{
+			Generated: time.Now().UTC(),
+			Title:     title,
+			Count:     len(earthquakes),
+		},
+		Features: make([]geometry.Feature, 0, len(earthquakes)),
+	}
+
+	for _, eq := range earthquakes {
+		collection.Features = append(collection.Features, geometry.Feature{
+			Type: "Feature",
+			ID:   eq.ID,
+			Geometry: geometry.Geometry{
+				Type:        "Point",
+				Coordinates: []float64{eq.Longitude, eq.Latitude, eq.Depth},
+			},
+			Properties: featureProperties(eq),
+		})
+	}
+
+	return collection
+}
+
+// WriteGeoJSON escribe la FeatureCollection de los sismos
+func WriteGeoJSON(w io.Writer, earthquakes []models.Earthquake, title string) error {
+	return json.NewEncoder(w).Encode(GeoJSON(earthquakes, title))
+}
+
+// featureProperties retorna las propiedades de un sismo; los tiempos van en ISO 8601 UTC
+func featureProperties(eq models.Earthquake) map[string]interface{} {
+	properties := map[string]interface{}{
+		"magnitude":    eq.Magnitude,
+		"mag":          eq.Magnitude, // Nombre de USGS, para reutilizar estilos hechos para sus feeds
+		"place":        eq.Location,
+		"time":         formatISOTime(eq.Time),
+		"depth":        eq.Depth,
+		"source":       eq.Source,
+		"oceano":       eq.Oceano,
+		"oceanoRegion": eq.OceanoRegion,
+	}
+
+	if eq.URL != "" {
+		properties["url"] = eq.URL
+	}
+	if !eq.UpdatedAt.IsZero() {
+		properties["updated"] = formatISOTime(eq.UpdatedAt)
+	}
+	if eq.ThreatLevel != "" {
+		properties["threatLevel"] = eq.ThreatLevel
+	}
+	if eq.SequenceID != "" {
+		properties["sequenceId"] = eq.SequenceID
+	}
+	if eq.CoastDistanceKm != nil {
+		properties["coastDistanceKm"] = *eq.CoastDistanceKm
+	}
+	if eq.DistanceKm != nil {
+		properties["distanceKm"] = *eq.DistanceKm
+	}
+	if eq.Revision > 0 {
+		properties["revision"] = eq.Revision
+	}
+
+	return properties
+}
+
+// formatISOTime formatea un tiempo en ISO 8601 (RFC 3339) UTC con milisegundos
+func formatISOTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
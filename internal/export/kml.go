@@ -0,0 +1,177 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// KMLContentType es el tipo MIME de KML
+const KMLContentType = "application/vnd.google-earth.kml+xml"
+
+// Colores KML (aabbggrr) por océano y región
+var regionColors = map[string]string{
+	"Pacifico/local":    "ff0000ff", // rojo
+	"Pacifico/regional": "ff0080ff", // naranja
+	"Pacifico/lejano":   "ff00ffff", // amarillo
+	"Caribe/local":      "ffff0000", // azul
+	"Caribe/regional":   "ffff8000", // azul claro
+	"Caribe/lejano":     "ffffff00", // cian
+}
+
+// uncategorizedColor es el color de los sismos fuera de todas las zonas (gris)
+const uncategorizedColor = "ff999999"
+
+// magnitudeClasses son los límites inferiores de las clases de magnitud y la escala del ícono
+var magnitudeClasses = []struct {
+	min   float64
+	scale float64
+}{
+	{7, 2.2},
+	{6, 1.8},
+	{5, 1.4},
+	{4, 1.0},
+	{0, 0.7},
+}
+
+// iconURL es el ícono de los sismos (Google Earth lo colorea con el estilo)
+const iconURL = "http://maps.google.com/mapfiles/kml/shapes/shaded_dot.png"
+
+type kmlDocument struct {
+	XMLName  xml.Name `xml:"kml"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Document kmlBody  `xml:"Document"`
+}
+
+type kmlBody struct {
+	Name    string      `xml:"name"`
+	Styles  []kmlStyle  `xml:"Style"`
+	Folders []kmlFolder `xml:"Folder"`
+}
+
+type kmlStyle struct {
+	ID    string `xml:"id,attr"`
+	Color string `xml:"IconStyle>color"`
+	Scale string `xml:"IconStyle>scale"`
+	Icon  string `xml:"IconStyle>Icon>href"`
+}
+
+type kmlFolder struct {
+	Name       string         `xml:"name"`
+	Placemarks []kmlPlacemark `xml:"Placemark"`
+}
+
+type kmlPlacemark struct {
+	ID          string `xml:"id,attr"`
+	Name        string `xml:"name"`
+	Description string `xml:"description"`
+	When        string `xml:"TimeStamp>when"`
+	StyleURL    string `xml:"styleUrl"`
+	Coordinates string `xml:"Point>coordinates"`
+}
+
+// WriteKML escribe los sismos como KML para Google Earth
+// Hay una carpeta por océano y región; el color del ícono depende de la región y
+// su tamaño de la magnitud.
+func WriteKML(w io.Writer, earthquakes []models.Earthquake, title string) error {
+	doc := kmlDocument{
+		Xmlns:    "http://www.opengis.net/kml/2.2",
+		Document: kmlBody{Name: title},
+	}
+
+	styles := make(map[string]bool)
+	folders := make(map[string]int) // Nombre de la carpeta -> posición
+
+	for _, eq := range earthquakes {
+		zone := zoneKey(eq)
+		class, scale := magnitudeClass(eq.Magnitude)
+		styleID := fmt.Sprintf("%s-m%d", styleZone(zone), class)
+
+		if !styles[styleID] {
+			styles[styleID] = true
+			doc.Document.Styles = append(doc.Document.Styles, kmlStyle{
+				ID:    styleID,
+				Color: zoneColor(zone),
+				Scale: formatFloat(scale),
+				Icon:  iconURL,
+			})
+		}
+
+		position, exists := folders[zone]
+		if !exists {
+			position = len(doc.Document.Folders)
+			folders[zone] = position
+			doc.Document.Folders = append(doc.Document.Folders, kmlFolder{Name: zone})
+		}
+
+		folder := &doc.Document.Folders[position]
+		folder.Placemarks = append(folder.Placemarks, kmlPlacemark{
+			ID:          eq.ID,
+			Name:        fmt.Sprintf("M%.1f - %s", eq.Magnitude, eq.Location),
+			Description: kmlDescription(eq),
+			When:        formatISOTime(eq.Time),
+			StyleURL:    "#" + styleID,
+			Coordinates: formatFloat(eq.Longitude) + "," + formatFloat(eq.Latitude),
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// zoneKey retorna "Oceano/region" o Uncategorized
+func zoneKey(eq models.Earthquake) string {
+	if !eq.IsCategorized() {
+		return models.Uncategorized
+	}
+	return eq.Oceano + "/" + eq.OceanoRegion
+}
+
+// styleZone convierte la zona en un identificador válido para un estilo
+func styleZone(zone string) string {
+	id := []byte(zone)
+	for i, c := range id {
+		if c == '/' || c == ' ' {
+			id[i] = '-'
+		}
+	}
+	return string(id)
+}
+
+// zoneColor retorna el color KML de la zona
+func zoneColor(zone string) string {
+	if color, exists := regionColors[zone]; exists {
+		return color
+	}
+	return uncategorizedColor
+}
+
+// magnitudeClass retorna la clase de magnitud (entero inferior) y la escala del ícono
+func magnitudeClass(magnitude float64) (int, float64) {
+	for _, class := range magnitudeClasses {
+		if magnitude >= class.min {
+			return int(class.min), class.scale
+		}
+	}
+	last := magnitudeClasses[len(magnitudeClasses)-1]
+	return int(last.min), last.scale
+}
+
+// kmlDescription retorna la descripción del globo de un sismo
+func kmlDescription(eq models.Earthquake) string {
+	description := fmt.Sprintf("Magnitud: %.1f\nProfundidad: %s km\nHora (UTC): %s\nFuente: %s\nZona: %s",
+		eq.Magnitude, formatFloat(eq.Depth), formatISOTime(eq.Time), eq.Source, zoneKey(eq))
+	if eq.ThreatLevel != "" {
+		description += "\nAmenaza: " + eq.ThreatLevel
+	}
+	if eq.URL != "" {
+		description += "\n" + eq.URL
+	}
+	return description
+}
@@ -0,0 +1,134 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// QuakeMLContentType es el tipo MIME usado para QuakeML (no tiene uno registrado)
+const QuakeMLContentType = "application/xml"
+
+// quakeMLIDPrefix es el prefijo de los identificadores de recursos (publicID)
+const quakeMLIDPrefix = "smi:evida"
+
+type quakeML struct {
+	XMLName    xml.Name        `xml:"q:quakeml"`
+	XmlnsQ     string          `xml:"xmlns:q,attr"`
+	Xmlns      string          `xml:"xmlns,attr"`
+	Parameters eventParameters `xml:"eventParameters"`
+}
+
+type eventParameters struct {
+	PublicID string         `xml:"publicID,attr"`
+	Events   []quakeMLEvent `xml:"event"`
+}
+
+type quakeMLEvent struct {
+	PublicID             string              `xml:"publicID,attr"`
+	Description          *quakeMLDescription `xml:"description,omitempty"`
+	Origin               quakeMLOrigin       `xml:"origin"`
+	Magnitude            quakeMLMagnitude    `xml:"magnitude"`
+	PreferredOriginID    string              `xml:"preferredOriginID"`
+	PreferredMagnitudeID string              `xml:"preferredMagnitudeID"`
+	Type                 string              `xml:"type"`
+	CreationInfo         quakeMLCreationInfo `xml:"creationInfo"`
+}
+
+type quakeMLDescription struct {
+	Text string `xml:"text"`
+	Type string `xml:"type"`
+}
+
+type quakeMLOrigin struct {
+	PublicID     string              `xml:"publicID,attr"`
+	Time         quakeMLValue        `xml:"time"`
+	Latitude     quakeMLValue        `xml:"latitude"`
+	Longitude    quakeMLValue        `xml:"longitude"`
+	Depth        quakeMLValue        `xml:"depth"` // metros
+	CreationInfo quakeMLCreationInfo `xml:"creationInfo"`
+}
+
+type quakeMLMagnitude struct {
+	PublicID     string              `xml:"publicID,attr"`
+	Mag          quakeMLValue        `xml:"mag"`
+	OriginID     string              `xml:"originID"`
+	CreationInfo quakeMLCreationInfo `xml:"creationInfo"`
+}
+
+type quakeMLValue struct {
+	Value string `xml:"value"`
+}
+
+type quakeMLCreationInfo struct {
+	AgencyID     string `xml:"agencyID"`
+	CreationTime string `xml:"creationTime,omitempty"`
+	Version      string `xml:"version,omitempty"`
+}
+
+// WriteQuakeML escribe los sismos como QuakeML 1.2 (BED): un event por sismo con su
+// origen y magnitud preferidos; la agencia es la fuente del sismo
+func WriteQuakeML(w io.Writer, earthquakes []models.Earthquake) error {
+	doc := quakeML{
+		XmlnsQ: "http://quakeml.org/xmlns/quakeml/1.2",
+		Xmlns:  "http://quakeml.org/xmlns/bed/1.2",
+		Parameters: eventParameters{
+			PublicID: quakeMLIDPrefix + "/eventParameters",
+			Events:   make([]quakeMLEvent, 0, len(earthquakes)),
+		},
+	}
+
+	for _, eq := range earthquakes {
+		id := url.PathEscape(eq.ID)
+		originID := quakeMLIDPrefix + "/origin/" + id
+		magnitudeID := quakeMLIDPrefix + "/magnitude/" + id
+
+		info := quakeMLCreationInfo{AgencyID: eq.Source}
+		if !eq.UpdatedAt.IsZero() {
+			info.CreationTime = formatISOTime(eq.UpdatedAt)
+		} else if !eq.IngestedAt.IsZero() {
+			info.CreationTime = formatISOTime(eq.IngestedAt)
+		}
+		if eq.Revision > 0 {
+			info.Version = strconv.Itoa(eq.Revision)
+		}
+
+		event := quakeMLEvent{
+			PublicID: quakeMLIDPrefix + "/event/" + id,
+			Origin: quakeMLOrigin{
+				PublicID:     originID,
+				Time:         quakeMLValue{Value: formatISOTime(eq.Time)},
+				Latitude:     quakeMLValue{Value: formatFloat(eq.Latitude)},
+				Longitude:    quakeMLValue{Value: formatFloat(eq.Longitude)},
+				Depth:        quakeMLValue{Value: formatFloat(math.Round(eq.Depth * 1000))},
+				CreationInfo: quakeMLCreationInfo{AgencyID: eq.Source},
+			},
+			Magnitude: quakeMLMagnitude{
+				PublicID:     magnitudeID,
+				Mag:          quakeMLValue{Value: formatFloat(eq.Magnitude)},
+				OriginID:     originID,
+				CreationInfo: quakeMLCreationInfo{AgencyID: eq.Source},
+			},
+			PreferredOriginID:    originID,
+			PreferredMagnitudeID: magnitudeID,
+			Type:                 "earthquake",
+			CreationInfo:         info,
+		}
+		if eq.Location != "" {
+			event.Description = &quakeMLDescription{Text: eq.Location, Type: "region name"}
+		}
+
+		doc.Parameters.Events = append(doc.Parameters.Events, event)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	return encoder.Encode(doc)
+}
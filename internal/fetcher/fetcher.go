@@ -4,5 +4,8 @@ import "github.com/andresgallo/evida_backend_go/internal/models"
 
 // Fetcher es la interfaz que deben implementar todos los fetchers
 type Fetcher interface {
+	// Name retorna el nombre de la fuente (USGS, GEOFON, SGC)
+	Name() string
+
 	Fetch() ([]models.Earthquake, error)
 }
@@ -46,6 +46,11 @@ type GEOFONFeed struct {
 	Channel GEOFONChannel `xml:"channel"`
 }
 
+// Name retorna el nombre de la fuente
+func (f *GEOFONFetcher) Name() string {
+	return "GEOFON"
+}
+
 // Fetch obtiene los sismos recientes de GEOFON
 func (f *GEOFONFetcher) Fetch() ([]models.Earthquake, error) {
 	// Feed RSS de GEOFON con los últimos 50 sismos
@@ -67,6 +67,11 @@ type SGCResponse struct {
 	} `json:"features"`
 }
 
+// Name retorna el nombre de la fuente
+func (f *SGCFetcher) Name() string {
+	return "SGC"
+}
+
 // Fetch obtiene los sismos recientes del SGC
 // Retorna sismos de los últimos 5 días
 func (f *SGCFetcher) Fetch() ([]models.Earthquake, error) {
@@ -41,6 +41,11 @@ type USGSResponse struct {
 	} `json:"features"`
 }
 
+// Name retorna el nombre de la fuente
+func (f *USGSFetcher) Name() string {
+	return "USGS"
+}
+
 // Fetch obtiene los sismos recientes de USGS
 // Retorna sismos de la última semana con magnitud >= 4.5
 func (f *USGSFetcher) Fetch() ([]models.Earthquake, error) {
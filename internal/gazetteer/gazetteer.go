@@ -0,0 +1,72 @@
+package gazetteer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// Town representa un municipio o isla costera colombiana
+type Town struct {
+	Name       string  `json:"name"`
+	Department string  `json:"department"`
+	Type       string  `json:"type"` // municipio, corregimiento, isla
+	Lat        float64 `json:"lat"`
+	Lon        float64 `json:"lon"`
+}
+
+//go:embed towns.json
+var townsData []byte
+
+// towns contiene el gazetteer embebido de poblaciones costeras
+var towns []Town
+
+func init() {
+	if err := json.Unmarshal(townsData, &towns); err != nil {
+		panic(fmt.Sprintf("invalid embedded towns.json: %v", err))
+	}
+}
+
+// Towns retorna todas las poblaciones del gazetteer
+func Towns() []Town {
+	return towns
+}
+
+// NearestTowns retorna las n poblaciones más cercanas al punto, de la más cercana a la más lejana
+// El rumbo y la dirección se expresan desde la población hacia el punto ("35 km SW of Tumaco").
+func NearestTowns(point models.Point, n int) []models.TownDistance {
+	if n <= 0 {
+		return nil
+	}
+
+	result := make([]models.TownDistance, 0, len(towns))
+	for _, town := range towns {
+		location := models.Point{Lat: town.Lat, Lon: town.Lon}
+		distance := geometry.Distance(location, point)
+		bearing := geometry.Bearing(location, point)
+		direction := geometry.CompassDirection(bearing)
+
+		result = append(result, models.TownDistance{
+			Name:        town.Name,
+			Department:  town.Department,
+			DistanceKm:  math.Round(distance*10) / 10,
+			Bearing:     math.Round(bearing*10) / 10,
+			Direction:   direction,
+			Description: fmt.Sprintf("%.0f km %s of %s", distance, direction, town.Name),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DistanceKm < result[j].DistanceKm
+	})
+
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package geometry
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// earthRadiusKm es el radio medio de la Tierra usado en los cálculos geodésicos
+const earthRadiusKm = 6371.0
+
+// Coastline es una línea de costa con nombre, definida como una polilínea [lat, lon]
+type Coastline struct {
+	Name   string         `json:"name"`
+	Oceano string         `json:"oceano"`
+	Points []models.Point `json:"points"`
+}
+
+// CoastZone define una zona como "a menos de N km del tramo de costa X"
+// Los sismos dentro de la zona reciben el océano y la región indicados.
+type CoastZone struct {
+	Name          string  `json:"name"`
+	Coastline     string  `json:"coastline"`
+	MaxDistanceKm float64 `json:"maxDistanceKm"`
+	Oceano        string  `json:"oceano"`
+	Region        string  `json:"region"`
+}
+
+// coastlineFile es el formato del archivo de costas embebido
+type coastlineFile struct {
+	Coastlines []Coastline `json:"coastlines"`
+}
+
+//go:embed coastline.json
+var defaultCoastlineData []byte
+
+// defaultCoast contiene la costa colombiana simplificada
+// Se usa cuando el archivo de regiones no define "coastlines".
+var defaultCoast coastlineFile
+
+func init() {
+	if err := json.Unmarshal(defaultCoastlineData, &defaultCoast); err != nil {
+		panic(fmt.Sprintf("invalid embedded coastline.json: %v", err))
+	}
+}
+
+// Coastlines retorna las líneas de costa activas
+func Coastlines() []Coastline {
+	if regionData != nil && len(regionData.Coastlines) > 0 {
+		return regionData.Coastlines
+	}
+	return defaultCoast.Coastlines
+}
+
+// CoastZones retorna las zonas por distancia a la costa definidas en el archivo de regiones
+// No hay zonas por defecto: sin "coastZones" el polígono latlonPacificoLocal20Km sigue
+// siendo el que decide la zona local de 20 km.
+func CoastZones() []CoastZone {
+	if regionData == nil {
+		return nil
+	}
+	return regionData.CoastZones
+}
+
+// DistanceToCoast retorna la distancia en km a la línea de costa más cercana y su nombre
+// El tercer valor es false si no hay costas cargadas.
+func DistanceToCoast(point models.Point) (float64, string, bool) {
+	best := math.Inf(1)
+	name := ""
+	for _, coastline := range Coastlines() {
+		if len(coastline.Points) == 0 {
+			continue
+		}
+		if d := DistanceToPolyline(point, coastline.Points); d < best {
+			best = d
+			name = coastline.Name
+		}
+	}
+
+	if name == "" {
+		return 0, "", false
+	}
+	return best, name, true
+}
+
+// DistanceToCoastline retorna la distancia en km a la línea de costa con el nombre dado
+func DistanceToCoastline(point models.Point, name string) (float64, bool) {
+	for _, coastline := range Coastlines() {
+		if coastline.Name == name && len(coastline.Points) > 0 {
+			return DistanceToPolyline(point, coastline.Points), true
+		}
+	}
+	return 0, false
+}
+
+// matchCoastZone retorna la primera zona de costa que contiene al punto
+func matchCoastZone(point models.Point) (CoastZone, bool) {
+	for _, zone := range CoastZones() {
+		d, ok := DistanceToCoastline(point, zone.Coastline)
+		if ok && d <= zone.MaxDistanceKm {
+			return zone, true
+		}
+	}
+	return CoastZone{}, false
+}
+
+// DistanceToPolyline retorna la distancia geodésica mínima en km de un punto a una polilínea
+func DistanceToPolyline(point models.Point, line []models.Point) float64 {
+	if len(line) == 1 {
+		return Distance(point, line[0])
+	}
+
+	best := math.Inf(1)
+	for i := 0; i+1 < len(line); i++ {
+		if d := DistanceToSegment(point, line[i], line[i+1]); d < best {
+			best = d
+		}
+	}
+	return best
+}
+
+// DistanceToSegment retorna la distancia geodésica en km de p al arco de círculo máximo a-b
+// Usa la distancia cruzada (cross-track) y recurre a los extremos cuando la
+// proyección cae fuera del arco.
+func DistanceToSegment(p, a, b models.Point) float64 {
+	d13 := Distance(a, p) / earthRadiusKm
+	d12 := Distance(a, b) / earthRadiusKm
+	if d12 == 0 {
+		return d13 * earthRadiusKm
+	}
+
+	theta13 := Bearing(a, p) * math.Pi / 180
+	theta12 := Bearing(a, b) * math.Pi / 180
+
+	// La proyección cae antes de a
+	if math.Cos(theta13-theta12) < 0 {
+		return d13 * earthRadiusKm
+	}
+
+	dxt := math.Asin(math.Sin(d13) * math.Sin(theta13-theta12))
+	dat := math.Acos(math.Max(-1, math.Min(1, math.Cos(d13)/math.Cos(dxt))))
+
+	// La proyección cae después de b
+	if dat > d12 {
+		return Distance(b, p)
+	}
+
+	return math.Abs(dxt) * earthRadiusKm
+}
@@ -0,0 +1,105 @@
+package geometry
+
+import (
+	"errors"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// FeatureCollection representa una colección GeoJSON (RFC 7946)
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Version  string    `json:"version,omitempty"` // Hash del archivo de regiones
+	Features []Feature `json:"features"`
+}
+
+// Feature representa un elemento GeoJSON con su geometría y propiedades
+type Feature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id,omitempty"`
+	Geometry   Geometry               `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// Geometry representa una geometría GeoJSON
+// Coordinates depende del tipo: Point, Polygon o MultiPolygon
+type Geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// ErrRegionDataNotLoaded se retorna cuando se consultan regiones antes de cargarlas
+var ErrRegionDataNotLoaded = errors.New("region data not loaded")
+
+// RegionsGeoJSON retorna las regiones cargadas como una FeatureCollection GeoJSON
+// Cada capa de RegionData es un Feature de tipo MultiPolygon con su océano y región.
+// Si tolerance > 0 los polígonos se simplifican con Douglas-Peucker (tolerancia en grados).
+func RegionsGeoJSON(tolerance float64) (*FeatureCollection, error) {
+	if regionData == nil {
+		return nil, ErrRegionDataNotLoaded
+	}
+
+	collection := &FeatureCollection{
+		Type:     "FeatureCollection",
+		Version:  regionDataVersion,
+		Features: make([]Feature, 0),
+	}
+
+	for _, layer := range regionData.Layers() {
+		polygons := make([][][][2]float64, 0, len(layer.Polygons))
+		points := 0
+		for _, polygon := range layer.Polygons {
+			if len(polygon) < 3 {
+				continue
+			}
+			simplified := SimplifyPolygon(polygon, tolerance)
+			points += len(simplified)
+			polygons = append(polygons, [][][2]float64{ringCoordinates(simplified)})
+		}
+
+		if len(polygons) == 0 {
+			continue
+		}
+
+		properties := map[string]interface{}{
+			"name":         layer.Name,
+			"oceano":       layer.Oceano,
+			"oceanoRegion": layer.Region,
+			"points":       points,
+		}
+		if layer.Region == "" {
+			// La región depende de otras capas (ver RegionLayer.Note)
+			properties["oceanoRegion"] = nil
+			properties["note"] = layer.Note
+		}
+
+		collection.Features = append(collection.Features, Feature{
+			Type: "Feature",
+			ID:   layer.Key,
+			Geometry: Geometry{
+				Type:        "MultiPolygon",
+				Coordinates: polygons,
+			},
+			Properties: properties,
+		})
+	}
+
+	return collection, nil
+}
+
+// ringCoordinates convierte un polígono a un anillo GeoJSON [lon, lat] cerrado
+func ringCoordinates(polygon models.Polygon) [][2]float64 {
+	ring := make([][2]float64, 0, len(polygon)+1)
+	for _, point := range polygon {
+		ring = append(ring, [2]float64{point.Lon, point.Lat})
+	}
+
+	// GeoJSON exige que el primer y el último punto del anillo coincidan
+	first := polygon[0]
+	last := polygon[len(polygon)-1]
+	if first.Lat != last.Lat || first.Lon != last.Lon {
+		ring = append(ring, [2]float64{first.Lon, first.Lat})
+	}
+
+	return ring
+}
@@ -1,6 +1,7 @@
 package geometry
 
 import (
+	"fmt"
 	"log"
 	"math"
 
@@ -51,71 +52,112 @@ func rayIntersectsSegment(point, p1, p2 models.Point) bool {
 	return point.Lon < intersectionLon
 }
 
+// Categorization explica por qué un punto recibió su océano y región
+type Categorization struct {
+	Oceano      string `json:"oceano"`
+	Region      string `json:"region"`
+	Layer       string `json:"layer"`                       // Capa que decidió (clave en el archivo de regiones o "coastZone")
+	CoastZone   string `json:"coastZone,omitempty"`         // Zona por distancia a la costa que decidió
+	Description string `json:"description"`                 // Explicación legible
+	Version     string `json:"regionDataVersion,omitempty"` // Versión del archivo de regiones usado
+}
+
 // CategorizeEarthquake asigna océano y región a un sismo basándose en su ubicación
 func CategorizeEarthquake(eq *models.Earthquake) {
+	point := models.Point{
+		Lat: eq.Latitude,
+		Lon: eq.Longitude,
+	}
+
+	// Distancia a la costa más cercana (se calcula para todos los sismos)
+	if distance, coastline, ok := DistanceToCoast(point); ok {
+		eq.CoastDistanceKm = &distance
+		eq.CoastSegment = coastline
+	}
+
 	if regionData == nil {
 		log.Printf("⚠️  Datos de regiones no cargados")
 		return
 	}
 
-	point := models.Point{
-		Lat: eq.Latitude,
-		Lon: eq.Longitude,
+	categorization := categorize(point)
+	eq.Oceano = categorization.Oceano
+	eq.OceanoRegion = categorization.Region
+}
+
+// ExplainCategorization retorna la categoría de un punto junto con la capa que la decidió
+func ExplainCategorization(point models.Point) Categorization {
+	if regionData == nil {
+		return Categorization{
+			Layer:       "none",
+			Description: "Datos de regiones no cargados",
+		}
+	}
+	return categorize(point)
+}
+
+// categorize evalúa las capas en orden y retorna la primera que contiene al punto
+func categorize(point models.Point) Categorization {
+	categorization := Categorization{Version: regionDataVersion}
+	assign := func(oceano, region, layer, description string) Categorization {
+		categorization.Oceano = oceano
+		categorization.Region = region
+		categorization.Layer = layer
+		categorization.Description = description
+		return categorization
 	}
 
 	// Determinar región del océano Pacífico con subregión
 	if PointInPolygon(point, regionData.LatlonCPWorld) {
-		eq.Oceano = "Pacifico"
-		eq.OceanoRegion = determinarRegionPacifico(point)
-		return
+		region := determinarRegionPacifico(point)
+		return assign("Pacifico", region, "latlonCPWorld",
+			fmt.Sprintf("Dentro del polígono Pacífico CP; subregión %s", region))
 	}
 
 	// Pacífico Local
 	if PointInPolygon(point, regionData.LatlonPacificoLocal) {
-		eq.Oceano = "Pacifico"
-		eq.OceanoRegion = "local"
-		return
+		return assign("Pacifico", "local", "latlonPacificoLocal", "Dentro del polígono Pacífico Local")
 	}
 
 	// Pacífico Regional
 	if len(regionData.LatlonPacificoRegional) > 0 && PointInPolygon(point, regionData.LatlonPacificoRegional) {
-		eq.Oceano = "Pacifico"
-		eq.OceanoRegion = "regional"
-		return
+		return assign("Pacifico", "regional", "latlonPacificoRegional", "Dentro del polígono Pacífico Regional")
 	}
 
-	// Pacífico Local 20Km
-	if len(regionData.LatlonPacificoLocal20Km) > 0 && PointInPolygon(point, regionData.LatlonPacificoLocal20Km) {
-		eq.Oceano = "Pacifico"
-		eq.OceanoRegion = "local"
-		return
+	// Zonas por distancia a la costa; solo si el archivo de regiones las define, y
+	// entonces reemplazan al polígono precalculado de 20 km
+	if zone, ok := matchCoastZone(point); ok {
+		categorization.CoastZone = zone.Name
+		return assign(zone.Oceano, zone.Region, "coastZone",
+			fmt.Sprintf("A menos de %g km de la costa %s (zona %s)", zone.MaxDistanceKm, zone.Coastline, zone.Name))
+	}
+
+	// Pacífico Local 20Km, si el archivo de regiones no define zonas de costa
+	if len(CoastZones()) == 0 && len(regionData.LatlonPacificoLocal20Km) > 0 &&
+		PointInPolygon(point, regionData.LatlonPacificoLocal20Km) {
+		return assign("Pacifico", "local", "latlonPacificoLocal20Km", "Dentro del polígono Pacífico Local 20 km")
 	}
 
 	// Caribe Lejano
 	if evaluarPuntosMultiples(point, regionData.LatlonCCWorld) {
-		eq.Oceano = "Caribe"
-		eq.OceanoRegion = "lejano"
-		return
+		return assign("Caribe", "lejano", "latlonCCWorld", "Dentro de los polígonos Caribe CC")
 	}
 
 	// Caribe Regional
 	if evaluarPuntosMultiples(point, regionData.LatlonCaribeRegional) {
-		eq.Oceano = "Caribe"
-		eq.OceanoRegion = "regional"
-		return
+		return assign("Caribe", "regional", "latlonCaribeRegional", "Dentro de los polígonos Caribe Regional")
 	}
 
 	// Caribe Local o Insular
-	if PointInPolygon(point, regionData.LatlonCaribeLocal) ||
-		PointInPolygon(point, regionData.LatlonCaribeLocalInsular) {
-		eq.Oceano = "Caribe"
-		eq.OceanoRegion = "local"
-		return
+	if PointInPolygon(point, regionData.LatlonCaribeLocal) {
+		return assign("Caribe", "local", "latlonCaribeLocal", "Dentro del polígono Caribe Local")
+	}
+	if PointInPolygon(point, regionData.LatlonCaribeLocalInsular) {
+		return assign("Caribe", "local", "latlonCaribeLocalInsular", "Dentro del polígono Caribe Local Insular")
 	}
 
 	// No categorizado
-	eq.Oceano = "Uncategorized"
-	eq.OceanoRegion = "Uncategorized"
+	return assign(models.Uncategorized, models.Uncategorized, "none", "Fuera de todas las zonas")
 }
 
 // determinarRegionPacifico determina la subregión dentro del Pacífico CP
@@ -141,7 +183,7 @@ func evaluarPuntosMultiples(point models.Point, polygons []models.Polygon) bool
 
 // Distance calcula la distancia en kilómetros entre dos puntos usando la fórmula de Haversine
 func Distance(p1, p2 models.Point) float64 {
-	const earthRadius = 6371.0 // Radio de la Tierra en km
+	const earthRadius = earthRadiusKm
 
 	lat1 := p1.Lat * math.Pi / 180
 	lat2 := p2.Lat * math.Pi / 180
@@ -156,3 +198,28 @@ func Distance(p1, p2 models.Point) float64 {
 
 	return earthRadius * c
 }
+
+// Bearing calcula el rumbo inicial en grados (0-360, desde el norte) para ir de p1 a p2
+func Bearing(p1, p2 models.Point) float64 {
+	lat1 := p1.Lat * math.Pi / 180
+	lat2 := p2.Lat * math.Pi / 180
+	deltaLon := (p2.Lon - p1.Lon) * math.Pi / 180
+
+	y := math.Sin(deltaLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(deltaLon)
+
+	bearing := math.Atan2(y, x) * 180 / math.Pi
+	return math.Mod(bearing+360, 360)
+}
+
+// compassPoints son los 16 puntos de la rosa de los vientos, en sentido horario desde el norte
+var compassPoints = []string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// CompassDirection convierte un rumbo en grados al punto cardinal más cercano (N, NNE, ..., NNW)
+func CompassDirection(bearing float64) string {
+	index := int(math.Round(math.Mod(bearing+360, 360)/22.5)) % len(compassPoints)
+	return compassPoints[index]
+}
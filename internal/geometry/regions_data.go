@@ -1,6 +1,8 @@
 package geometry
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"log"
 	"os"
@@ -18,10 +20,28 @@ type RegionData struct {
 	LatlonCaribeRegional     []models.Polygon `json:"latlonCaribeRegional"`
 	LatlonCaribeLocal        models.Polygon   `json:"latlonCaribeLocal"`
 	LatlonCaribeLocalInsular models.Polygon   `json:"latlonCaribeLocalInsular"`
+
+	// Opcionales: si no vienen en el archivo se usan los de coastline.json
+	Coastlines []Coastline `json:"coastlines,omitempty"`
+	CoastZones []CoastZone `json:"coastZones,omitempty"`
+}
+
+// RegionLayer describe una de las capas de polígonos de RegionData junto con
+// la categoría que asigna a los sismos que caen dentro de ella
+type RegionLayer struct {
+	Key      string           // Nombre del campo en el archivo JSON
+	Name     string           // Nombre legible
+	Oceano   string           // Pacifico, Caribe
+	Region   string           // local, regional, lejano ("" si depende de otras capas)
+	Note     string           // Cómo se decide la región cuando Region es ""
+	Polygons []models.Polygon // Polígonos de la capa
 }
 
 var regionData *RegionData
 
+// regionDataVersion es el hash SHA-256 del archivo de regiones cargado
+var regionDataVersion string
+
 // LoadRegionData carga los datos de regiones desde el archivo JSON
 func LoadRegionData(filePath string) error {
 	data, err := os.ReadFile(filePath)
@@ -34,11 +54,32 @@ func LoadRegionData(filePath string) error {
 		return err
 	}
 
+	regionDataVersion = sha256Hex(data)
+
 	log.Printf("✅ Datos de regiones cargados correctamente")
 	log.Printf("   - Pacífico CP: %d puntos", len(regionData.LatlonCPWorld))
 	log.Printf("   - Pacífico Local: %d puntos", len(regionData.LatlonPacificoLocal))
 	log.Printf("   - Caribe CC: %d polígonos", len(regionData.LatlonCCWorld))
 	log.Printf("   - Caribe Regional: %d polígonos", len(regionData.LatlonCaribeRegional))
+	log.Printf("   - Versión: %s", regionDataVersion[:12])
+
+	log.Printf("   - Costas: %d líneas, %d zonas por distancia", len(Coastlines()), len(CoastZones()))
+
+	// Advertir sobre zonas que apuntan a una costa inexistente
+	for _, zone := range CoastZones() {
+		if _, ok := DistanceToCoastline(models.Point{}, zone.Coastline); !ok {
+			log.Printf("⚠️  Zona %s: la costa %q no existe y la zona será ignorada", zone.Name, zone.Coastline)
+		}
+	}
+
+	// Advertir sobre polígonos que PointInPolygon nunca puede cumplir
+	for _, layer := range regionData.Layers() {
+		for i, polygon := range layer.Polygons {
+			if len(polygon) > 0 && len(polygon) < 3 {
+				log.Printf("⚠️  %s: el polígono %d tiene solo %d puntos y será ignorado", layer.Name, i, len(polygon))
+			}
+		}
+	}
 
 	return nil
 }
@@ -47,3 +88,30 @@ func LoadRegionData(filePath string) error {
 func GetRegionData() *RegionData {
 	return regionData
 }
+
+// sha256Hex retorna el hash SHA-256 de los datos en hexadecimal
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// RegionDataVersion retorna el hash SHA-256 del archivo de regiones cargado
+// Retorna una cadena vacía si aún no se han cargado datos
+func RegionDataVersion() string {
+	return regionDataVersion
+}
+
+// Layers retorna las capas de polígonos en el mismo orden en que las evalúa CategorizeEarthquake
+func (rd *RegionData) Layers() []RegionLayer {
+	return []RegionLayer{
+		{Key: "latlonCPWorld", Name: "Pacífico CP", Oceano: "Pacifico",
+			Note: "local o regional si el punto también está en latlonPacificoLocal o latlonPacificoRegional; lejano en el resto", Polygons: []models.Polygon{rd.LatlonCPWorld}},
+		{Key: "latlonPacificoLocal", Name: "Pacífico Local", Oceano: "Pacifico", Region: "local", Polygons: []models.Polygon{rd.LatlonPacificoLocal}},
+		{Key: "latlonPacificoRegional", Name: "Pacífico Regional", Oceano: "Pacifico", Region: "regional", Polygons: []models.Polygon{rd.LatlonPacificoRegional}},
+		{Key: "latlonPacificoLocal20Km", Name: "Pacífico Local 20Km", Oceano: "Pacifico", Region: "local", Polygons: []models.Polygon{rd.LatlonPacificoLocal20Km}},
+		{Key: "latlonCCWorld", Name: "Caribe CC", Oceano: "Caribe", Region: "lejano", Polygons: rd.LatlonCCWorld},
+		{Key: "latlonCaribeRegional", Name: "Caribe Regional", Oceano: "Caribe", Region: "regional", Polygons: rd.LatlonCaribeRegional},
+		{Key: "latlonCaribeLocal", Name: "Caribe Local", Oceano: "Caribe", Region: "local", Polygons: []models.Polygon{rd.LatlonCaribeLocal}},
+		{Key: "latlonCaribeLocalInsular", Name: "Caribe Local Insular", Oceano: "Caribe", Region: "local", Polygons: []models.Polygon{rd.LatlonCaribeLocalInsular}},
+	}
+}
@@ -0,0 +1,230 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// segmentIndex es una grilla uniforme sobre los segmentos de un anillo
+// Permite buscar cruces y distancias sin comparar todos los segmentos entre sí,
+// lo que es necesario con polígonos de cientos de miles de puntos.
+type segmentIndex struct {
+	ring     models.Polygon
+	minLat   float64
+	minLon   float64
+	cellSize float64
+	cols     int
+	rows     int
+	cells    map[int][]int
+}
+
+// newSegmentIndex construye el índice para el anillo (abierto) dado
+func newSegmentIndex(ring models.Polygon) *segmentIndex {
+	minLat, minLon := math.Inf(1), math.Inf(1)
+	maxLat, maxLon := math.Inf(-1), math.Inf(-1)
+	for _, p := range ring {
+		minLat = math.Min(minLat, p.Lat)
+		minLon = math.Min(minLon, p.Lon)
+		maxLat = math.Max(maxLat, p.Lat)
+		maxLon = math.Max(maxLon, p.Lon)
+	}
+
+	// Aproximadamente un segmento por celda
+	side := math.Ceil(math.Sqrt(float64(len(ring))))
+	cellSize := math.Max(maxLat-minLat, maxLon-minLon) / side
+	if cellSize <= 0 {
+		cellSize = 1e-6
+	}
+
+	idx := &segmentIndex{
+		ring:     ring,
+		minLat:   minLat,
+		minLon:   minLon,
+		cellSize: cellSize,
+		cols:     int((maxLon-minLon)/cellSize) + 1,
+		rows:     int((maxLat-minLat)/cellSize) + 1,
+		cells:    make(map[int][]int),
+	}
+
+	n := len(ring)
+	for i := 0; i < n; i++ {
+		p1, p2 := ring[i], ring[(i+1)%n]
+		idx.forEachCell(math.Min(p1.Lat, p2.Lat), math.Min(p1.Lon, p2.Lon),
+			math.Max(p1.Lat, p2.Lat), math.Max(p1.Lon, p2.Lon), func(key int) {
+				idx.cells[key] = append(idx.cells[key], i)
+			})
+	}
+
+	return idx
+}
+
+// segment retorna los extremos del segmento i
+func (idx *segmentIndex) segment(i int) (models.Point, models.Point) {
+	return idx.ring[i], idx.ring[(i+1)%len(idx.ring)]
+}
+
+// forEachCell recorre las celdas que cubren el rectángulo dado
+func (idx *segmentIndex) forEachCell(minLat, minLon, maxLat, maxLon float64, fn func(key int)) {
+	row0 := clampInt(int((minLat-idx.minLat)/idx.cellSize), 0, idx.rows-1)
+	row1 := clampInt(int((maxLat-idx.minLat)/idx.cellSize), 0, idx.rows-1)
+	col0 := clampInt(int((minLon-idx.minLon)/idx.cellSize), 0, idx.cols-1)
+	col1 := clampInt(int((maxLon-idx.minLon)/idx.cellSize), 0, idx.cols-1)
+
+	for row := row0; row <= row1; row++ {
+		for col := col0; col <= col1; col++ {
+			fn(row*idx.cols + col)
+		}
+	}
+}
+
+// nearestDistanceKm retorna la distancia en km del punto al segmento más cercano,
+// buscando solo hasta maxKm. El segundo valor es false si no hay segmentos a esa distancia.
+func (idx *segmentIndex) nearestDistanceKm(p models.Point, maxKm float64) (float64, bool) {
+	latDeg := maxKm / 111.2
+	lonDeg := latDeg / math.Max(math.Cos(p.Lat*math.Pi/180), 0.01)
+
+	best := math.Inf(1)
+	idx.forEachCell(p.Lat-latDeg, p.Lon-lonDeg, p.Lat+latDeg, p.Lon+lonDeg, func(key int) {
+		for _, i := range idx.cells[key] {
+			a, b := idx.segment(i)
+			if d := Distance(p, nearestPointOnSegment(p, a, b)); d < best {
+				best = d
+			}
+		}
+	})
+
+	if best > maxKm {
+		return 0, false
+	}
+	return best, true
+}
+
+// forEachSegmentPair llama fn por cada par de segmentos no contiguos del anillo que se tocan
+func forEachSegmentPair(ring models.Polygon, fn func(i, j int)) {
+	idx := newSegmentIndex(ring)
+	reported := make(map[[2]int]bool)
+
+	for _, segments := range idx.cells {
+		for x := 0; x < len(segments); x++ {
+			for y := x + 1; y < len(segments); y++ {
+				i, j := segments[x], segments[y]
+				if i > j {
+					i, j = j, i
+				}
+				if reported[[2]int{i, j}] {
+					continue
+				}
+
+				a1, a2 := idx.segment(i)
+				b1, b2 := idx.segment(j)
+
+				// Segmentos que comparten un vértice (contiguos o con puntos duplicados) no cuentan
+				if a1 == a2 || b1 == b2 || a1 == b1 || a1 == b2 || a2 == b1 || a2 == b2 {
+					continue
+				}
+
+				if segmentsIntersect(a1, a2, b1, b2, true) {
+					reported[[2]int{i, j}] = true
+					fn(i, j)
+				}
+			}
+		}
+	}
+}
+
+// firstCrossing busca un cruce propio entre los bordes de dos anillos
+func firstCrossing(ringA, ringB models.Polygon) (models.Point, bool) {
+	idx := newSegmentIndex(ringB)
+
+	n := len(ringA)
+	for i := 0; i < n; i++ {
+		a1, a2 := ringA[i], ringA[(i+1)%n]
+
+		var found *models.Point
+		idx.forEachCell(math.Min(a1.Lat, a2.Lat), math.Min(a1.Lon, a2.Lon),
+			math.Max(a1.Lat, a2.Lat), math.Max(a1.Lon, a2.Lon), func(key int) {
+				if found != nil {
+					return
+				}
+				for _, j := range idx.cells[key] {
+					b1, b2 := idx.segment(j)
+					if segmentsIntersect(a1, a2, b1, b2, false) {
+						found = &a1
+						return
+					}
+				}
+			})
+
+		if found != nil {
+			return *found, true
+		}
+	}
+
+	return models.Point{}, false
+}
+
+// segmentsIntersect determina si los segmentos p1-p2 y p3-p4 se intersectan
+// Con inclusive=false solo cuentan los cruces propios (no el contacto en extremos ni la colinealidad).
+func segmentsIntersect(p1, p2, p3, p4 models.Point, inclusive bool) bool {
+	d1 := orientation(p3, p4, p1)
+	d2 := orientation(p3, p4, p2)
+	d3 := orientation(p1, p2, p3)
+	d4 := orientation(p1, p2, p4)
+
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) &&
+		((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+
+	if !inclusive {
+		return false
+	}
+
+	return (d1 == 0 && onSegment(p3, p4, p1)) ||
+		(d2 == 0 && onSegment(p3, p4, p2)) ||
+		(d3 == 0 && onSegment(p1, p2, p3)) ||
+		(d4 == 0 && onSegment(p1, p2, p4))
+}
+
+// orientation retorna el signo del producto cruz (b-a) x (c-a)
+func orientation(a, b, c models.Point) float64 {
+	return (b.Lon-a.Lon)*(c.Lat-a.Lat) - (b.Lat-a.Lat)*(c.Lon-a.Lon)
+}
+
+// onSegment indica si p (colineal con a-b) está dentro del rectángulo del segmento
+func onSegment(a, b, p models.Point) bool {
+	return p.Lon >= math.Min(a.Lon, b.Lon) && p.Lon <= math.Max(a.Lon, b.Lon) &&
+		p.Lat >= math.Min(a.Lat, b.Lat) && p.Lat <= math.Max(a.Lat, b.Lat)
+}
+
+// nearestPointOnSegment proyecta p sobre el segmento a-b en un plano local
+// La longitud se escala por cos(lat) para que la proyección sea aproximadamente equidistante.
+func nearestPointOnSegment(p, a, b models.Point) models.Point {
+	scale := math.Cos(p.Lat * math.Pi / 180)
+	dx := (b.Lon - a.Lon) * scale
+	dy := b.Lat - a.Lat
+
+	if dx == 0 && dy == 0 {
+		return a
+	}
+
+	t := ((p.Lon-a.Lon)*scale*dx + (p.Lat-a.Lat)*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+
+	return models.Point{
+		Lat: a.Lat + t*(b.Lat-a.Lat),
+		Lon: a.Lon + t*(b.Lon-a.Lon),
+	}
+}
+
+// clampInt limita v al rango [min, max]
+func clampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
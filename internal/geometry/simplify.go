@@ -0,0 +1,73 @@
+package geometry
+
+import (
+	"math"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// SimplifyPolygon reduce el número de vértices de un polígono con el algoritmo Douglas-Peucker
+// La tolerancia se expresa en grados; con tolerancia <= 0 se retorna el polígono original.
+// Si la simplificación dejara menos de 3 vértices se conserva el polígono original.
+func SimplifyPolygon(polygon models.Polygon, tolerance float64) models.Polygon {
+	if tolerance <= 0 || len(polygon) <= 3 {
+		return polygon
+	}
+
+	keep := make([]bool, len(polygon))
+	keep[0] = true
+	keep[len(polygon)-1] = true
+
+	// Pila explícita de segmentos pendientes: los polígonos de la costa tienen
+	// cientos de miles de puntos y la recursión podría ser muy profunda
+	type segment struct{ first, last int }
+	stack := []segment{{0, len(polygon) - 1}}
+
+	for len(stack) > 0 {
+		seg := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		maxDist := 0.0
+		index := -1
+		for i := seg.first + 1; i < seg.last; i++ {
+			d := perpendicularDistance(polygon[i], polygon[seg.first], polygon[seg.last])
+			if d > maxDist {
+				maxDist = d
+				index = i
+			}
+		}
+
+		if index != -1 && maxDist > tolerance {
+			keep[index] = true
+			stack = append(stack, segment{seg.first, index}, segment{index, seg.last})
+		}
+	}
+
+	simplified := make(models.Polygon, 0)
+	for i, point := range polygon {
+		if keep[i] {
+			simplified = append(simplified, point)
+		}
+	}
+
+	if len(simplified) < 3 {
+		return polygon
+	}
+	return simplified
+}
+
+// perpendicularDistance calcula la distancia (en grados) de un punto al segmento a-b
+func perpendicularDistance(p, a, b models.Point) float64 {
+	dx := b.Lon - a.Lon
+	dy := b.Lat - a.Lat
+
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p.Lon-a.Lon, p.Lat-a.Lat)
+	}
+
+	// Proyección del punto sobre el segmento, limitada a sus extremos
+	t := ((p.Lon-a.Lon)*dx + (p.Lat-a.Lat)*dy) / (dx*dx + dy*dy)
+	t = math.Max(0, math.Min(1, t))
+
+	return math.Hypot(p.Lon-(a.Lon+t*dx), p.Lat-(a.Lat+t*dy))
+}
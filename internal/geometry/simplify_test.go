@@ -0,0 +1,80 @@
+package geometry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// ring convierte pares [lat, lon] en un polígono
+func ring(pairs ...[2]float64) models.Polygon {
+	polygon := make(models.Polygon, len(pairs))
+	for i, pair := range pairs {
+		polygon[i] = models.Point{Lat: pair[0], Lon: pair[1]}
+	}
+	return polygon
+}
+
+func TestSimplifyPolygon(t *testing.T) {
+	// Cuadrado cerrado con un punto en la mitad de cada lado; el lado norte se desvía 0.05°
+	square := ring(
+		[2]float64{0, 0}, [2]float64{0, 0.5}, [2]float64{0, 1}, [2]float64{0.5, 1},
+		[2]float64{1, 1}, [2]float64{1.05, 0.5}, [2]float64{1, 0}, [2]float64{0.5, 0}, [2]float64{0, 0},
+	)
+
+	tests := []struct {
+		name      string
+		polygon   models.Polygon
+		tolerance float64
+		want      models.Polygon
+	}{
+		{
+			name:      "tolerancia mayor que la desviación",
+			polygon:   square,
+			tolerance: 0.1,
+			want:      ring([2]float64{0, 0}, [2]float64{0, 1}, [2]float64{1, 1}, [2]float64{1, 0}, [2]float64{0, 0}),
+		},
+		{
+			name:      "tolerancia menor que la desviación",
+			polygon:   square,
+			tolerance: 0.01,
+			want: ring([2]float64{0, 0}, [2]float64{0, 1}, [2]float64{1, 1}, [2]float64{1.05, 0.5},
+				[2]float64{1, 0}, [2]float64{0, 0}),
+		},
+		{
+			name:      "tolerancia cero",
+			polygon:   square,
+			tolerance: 0,
+			want:      square,
+		},
+		{
+			name:      "tres puntos",
+			polygon:   ring([2]float64{0, 0}, [2]float64{0, 1}, [2]float64{1, 1}),
+			tolerance: 10,
+			want:      ring([2]float64{0, 0}, [2]float64{0, 1}, [2]float64{1, 1}),
+		},
+		{
+			name:      "quedarían menos de 3 puntos",
+			polygon:   ring([2]float64{0, 0}, [2]float64{0, 1}, [2]float64{0, 2}, [2]float64{0, 3}),
+			tolerance: 0.1,
+			want:      ring([2]float64{0, 0}, [2]float64{0, 1}, [2]float64{0, 2}, [2]float64{0, 3}),
+		},
+		{
+			name: "anillo abierto conserva sus extremos",
+			polygon: ring([2]float64{0, 0}, [2]float64{0, 1}, [2]float64{0.01, 1.5}, [2]float64{0, 2},
+				[2]float64{1, 2}, [2]float64{1, 0.1}),
+			tolerance: 0.05,
+			want:      ring([2]float64{0, 0}, [2]float64{0, 2}, [2]float64{1, 2}, [2]float64{1, 0.1}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SimplifyPolygon(tt.polygon, tt.tolerance)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SimplifyPolygon() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
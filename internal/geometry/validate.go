@@ -0,0 +1,331 @@
+package geometry
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// Severidad de los problemas encontrados al validar un archivo de regiones
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// maxIssuesPerCheck limita los problemas reportados por chequeo y polígono
+// para que un polígono muy dañado no inunde el reporte
+const maxIssuesPerCheck = 20
+
+// ValidationIssue describe un problema encontrado en un polígono
+type ValidationIssue struct {
+	Layer    string        `json:"layer"`
+	Polygon  int           `json:"polygon"`
+	Check    string        `json:"check"`
+	Severity string        `json:"severity"`
+	Message  string        `json:"message"`
+	Location *models.Point `json:"location,omitempty"`
+}
+
+// ValidationReport contiene el resultado de validar un archivo de regiones
+type ValidationReport struct {
+	Version  string            `json:"version"`
+	Issues   []ValidationIssue `json:"issues"`
+	Errors   int               `json:"errors"`
+	Warnings int               `json:"warnings"`
+}
+
+// ValidationOptions configura los chequeos que comparan zonas entre sí
+type ValidationOptions struct {
+	// GapToleranceKm es la distancia máxima a la que un vértice de una zona
+	// se considera "casi" en el borde de la zona vecina (hueco entre zonas)
+	GapToleranceKm float64
+}
+
+// exclusivePairs son las capas que no deben superponerse: CategorizeEarthquake
+// evalúa las capas en orden y una superposición hace que la segunda nunca se asigne.
+// Pacífico Local puede estar dentro de Pacífico Regional (se evalúa antes), así que
+// ese par no se incluye.
+var exclusivePairs = [][2]string{
+	{"latlonCCWorld", "latlonCaribeRegional"},
+	{"latlonCaribeRegional", "latlonCaribeLocal"},
+	{"latlonCaribeRegional", "latlonCaribeLocalInsular"},
+	{"latlonCPWorld", "latlonCCWorld"},
+	{"latlonCPWorld", "latlonCaribeRegional"},
+	{"latlonCPWorld", "latlonCaribeLocal"},
+}
+
+// adjacentPairs son las capas que deben compartir borde a lo largo de la costa
+var adjacentPairs = [][2]string{
+	{"latlonPacificoLocal", "latlonPacificoRegional"},
+	{"latlonCaribeLocal", "latlonCaribeRegional"},
+}
+
+// add agrega un problema al reporte y actualiza los contadores
+func (r *ValidationReport) add(issue ValidationIssue) {
+	r.Issues = append(r.Issues, issue)
+	if issue.Severity == SeverityError {
+		r.Errors++
+	} else {
+		r.Warnings++
+	}
+}
+
+// ValidateRegionFile carga un archivo de regiones y valida sus polígonos
+// A diferencia de LoadRegionData, los puntos mal formados se reportan en lugar de abortar la carga.
+func ValidateRegionFile(filePath string, opts ValidationOptions) (*ValidationReport, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("error parsing region file: %w", err)
+	}
+
+	report := &ValidationReport{Issues: make([]ValidationIssue, 0)}
+	layers := make(map[string][]models.Polygon)
+
+	for _, layer := range (&RegionData{}).Layers() {
+		value, ok := raw[layer.Key]
+		if !ok {
+			report.add(ValidationIssue{
+				Layer: layer.Key, Polygon: -1, Check: "missing_layer", Severity: SeverityWarning,
+				Message: "layer not present in file",
+			})
+			continue
+		}
+
+		rings, err := decodeRawRings(value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing layer %s: %w", layer.Key, err)
+		}
+
+		polygons := make([]models.Polygon, 0, len(rings))
+		for i, ring := range rings {
+			polygons = append(polygons, decodeRing(report, layer.Key, i, ring))
+		}
+		layers[layer.Key] = polygons
+
+		for i, polygon := range polygons {
+			validatePolygon(report, layer.Key, i, polygon)
+		}
+	}
+
+	for _, pair := range exclusivePairs {
+		checkOverlap(report, pair[0], layers[pair[0]], pair[1], layers[pair[1]])
+	}
+
+	if opts.GapToleranceKm > 0 {
+		for _, pair := range adjacentPairs {
+			checkGaps(report, pair[0], layers[pair[0]], pair[1], layers[pair[1]], opts.GapToleranceKm)
+		}
+	}
+
+	report.Version = sha256Hex(data)
+
+	return report, nil
+}
+
+// decodeRawRings acepta tanto un polígono ([[lat, lon], ...]) como una lista de polígonos
+func decodeRawRings(value json.RawMessage) ([][][]float64, error) {
+	var single [][]float64
+	if err := json.Unmarshal(value, &single); err == nil {
+		return [][][]float64{single}, nil
+	}
+
+	var multiple [][][]float64
+	if err := json.Unmarshal(value, &multiple); err != nil {
+		return nil, err
+	}
+	return multiple, nil
+}
+
+// decodeRing convierte los pares [lat, lon] en un polígono, reportando los puntos incompletos
+func decodeRing(report *ValidationReport, layer string, index int, ring [][]float64) models.Polygon {
+	polygon := make(models.Polygon, 0, len(ring))
+	for i, pair := range ring {
+		if len(pair) < 2 {
+			report.add(ValidationIssue{
+				Layer: layer, Polygon: index, Check: "short_point", Severity: SeverityError,
+				Message: fmt.Sprintf("point %d has %d coordinates, expected [lat, lon]", i, len(pair)),
+			})
+			continue
+		}
+		polygon = append(polygon, models.Point{Lat: pair[0], Lon: pair[1]})
+	}
+	return polygon
+}
+
+// validatePolygon ejecuta los chequeos que solo dependen de un polígono
+func validatePolygon(report *ValidationReport, layer string, index int, polygon models.Polygon) {
+	// Las capas opcionales pueden venir vacías (CategorizeEarthquake las omite)
+	if len(polygon) == 0 {
+		return
+	}
+
+	ring := openRing(polygon)
+	if len(ring) < 3 {
+		report.add(ValidationIssue{
+			Layer: layer, Polygon: index, Check: "too_few_points", Severity: SeverityError,
+			Message: fmt.Sprintf("polygon has %d distinct points, PointInPolygon needs at least 3", len(ring)),
+		})
+		return
+	}
+
+	if len(ring) == len(polygon) {
+		report.add(ValidationIssue{
+			Layer: layer, Polygon: index, Check: "unclosed_ring", Severity: SeverityWarning,
+			Message:  "first and last points differ (ring is closed implicitly)",
+			Location: &polygon[0],
+		})
+	}
+
+	duplicates := 0
+	for i := 1; i < len(ring); i++ {
+		if ring[i] == ring[i-1] {
+			duplicates++
+			if duplicates <= maxIssuesPerCheck {
+				report.add(ValidationIssue{
+					Layer: layer, Polygon: index, Check: "duplicate_vertex", Severity: SeverityWarning,
+					Message:  fmt.Sprintf("point %d repeats the previous point", i),
+					Location: &ring[i],
+				})
+			}
+		}
+	}
+	reportTruncated(report, layer, index, "duplicate_vertex", SeverityWarning, duplicates)
+
+	area := signedArea(ring)
+	if math.Abs(area) < 1e-12 {
+		report.add(ValidationIssue{
+			Layer: layer, Polygon: index, Check: "zero_area", Severity: SeverityError,
+			Message: "polygon has zero area",
+		})
+		return
+	}
+	if area < 0 {
+		report.add(ValidationIssue{
+			Layer: layer, Polygon: index, Check: "winding", Severity: SeverityWarning,
+			Message: "exterior ring is clockwise, expected counter-clockwise (RFC 7946)",
+		})
+	}
+
+	crossings := 0
+	forEachSegmentPair(ring, func(i, j int) {
+		crossings++
+		if crossings <= maxIssuesPerCheck {
+			location := ring[i]
+			report.add(ValidationIssue{
+				Layer: layer, Polygon: index, Check: "self_intersection", Severity: SeverityError,
+				Message:  fmt.Sprintf("segment %d crosses segment %d", i, j),
+				Location: &location,
+			})
+		}
+	})
+	reportTruncated(report, layer, index, "self_intersection", SeverityError, crossings)
+}
+
+// reportTruncated agrega un resumen cuando un chequeo superó maxIssuesPerCheck
+func reportTruncated(report *ValidationReport, layer string, index int, check, severity string, total int) {
+	if total <= maxIssuesPerCheck {
+		return
+	}
+	report.add(ValidationIssue{
+		Layer: layer, Polygon: index, Check: check, Severity: severity,
+		Message: fmt.Sprintf("%d more occurrences not listed", total-maxIssuesPerCheck),
+	})
+}
+
+// checkOverlap reporta superposiciones entre dos capas que deberían ser excluyentes
+// Solo cuentan los cruces propios de bordes o la contención completa; compartir borde es válido.
+func checkOverlap(report *ValidationReport, layerA string, polygonsA []models.Polygon, layerB string, polygonsB []models.Polygon) {
+	for i, a := range polygonsA {
+		ringA := openRing(a)
+		if len(ringA) < 3 {
+			continue
+		}
+		for j, b := range polygonsB {
+			ringB := openRing(b)
+			if len(ringB) < 3 {
+				continue
+			}
+
+			var location *models.Point
+			if p, ok := firstCrossing(ringA, ringB); ok {
+				location = &p
+			} else if PointInPolygon(ringA[0], ringB) {
+				location = &ringA[0]
+			} else if PointInPolygon(ringB[0], ringA) {
+				location = &ringB[0]
+			}
+
+			if location != nil {
+				report.add(ValidationIssue{
+					Layer: layerA, Polygon: i, Check: "overlap", Severity: SeverityError,
+					Message:  fmt.Sprintf("overlaps %s polygon %d, which should be exclusive", layerB, j),
+					Location: location,
+				})
+			}
+		}
+	}
+}
+
+// checkGaps reporta vértices de una capa que quedan cerca, pero no sobre, el borde de
+// la capa vecina: son huecos angostos donde un sismo quedaría sin categorizar
+func checkGaps(report *ValidationReport, layerA string, polygonsA []models.Polygon, layerB string, polygonsB []models.Polygon, toleranceKm float64) {
+	const onBorderKm = 0.05
+
+	for j, b := range polygonsB {
+		ringB := openRing(b)
+		if len(ringB) < 3 {
+			continue
+		}
+		index := newSegmentIndex(ringB)
+
+		for i, a := range polygonsA {
+			gaps := 0
+			for _, p := range openRing(a) {
+				d, ok := index.nearestDistanceKm(p, toleranceKm)
+				if !ok || d <= onBorderKm || PointInPolygon(p, ringB) {
+					continue
+				}
+				gaps++
+				if gaps <= maxIssuesPerCheck {
+					location := p
+					report.add(ValidationIssue{
+						Layer: layerA, Polygon: i, Check: "coastal_gap", Severity: SeverityWarning,
+						Message:  fmt.Sprintf("vertex is %.2f km away from %s polygon %d", d, layerB, j),
+						Location: &location,
+					})
+				}
+			}
+			reportTruncated(report, layerA, i, "coastal_gap", SeverityWarning, gaps)
+		}
+	}
+}
+
+// openRing retorna el anillo sin el punto de cierre repetido
+func openRing(polygon models.Polygon) models.Polygon {
+	n := len(polygon)
+	if n > 1 && polygon[0] == polygon[n-1] {
+		return polygon[:n-1]
+	}
+	return polygon
+}
+
+// signedArea calcula el área con signo (en grados²) con la fórmula del polígono de Gauss
+// Positiva para anillos en sentido antihorario
+func signedArea(ring models.Polygon) float64 {
+	area := 0.0
+	n := len(ring)
+	for i := 0; i < n; i++ {
+		p1 := ring[i]
+		p2 := ring[(i+1)%n]
+		area += p1.Lon*p2.Lat - p2.Lon*p1.Lat
+	}
+	return area / 2
+}
@@ -0,0 +1,217 @@
+package geometry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// square retorna un anillo cerrado antihorario de lado size (grados) con esquina en (lat, lon)
+func square(lat, lon, size float64) [][]float64 {
+	return [][]float64{
+		{lat, lon},
+		{lat, lon + size},
+		{lat + size, lon + size},
+		{lat + size, lon},
+		{lat, lon},
+	}
+}
+
+// validLayers retorna un archivo de regiones sin problemas: capas separadas entre sí
+func validLayers() map[string]interface{} {
+	return map[string]interface{}{
+		"latlonCPWorld":            square(0, -100, 1),
+		"latlonPacificoLocal":      square(0, -95, 1),
+		"latlonPacificoRegional":   square(0, -90, 1),
+		"latlonPacificoLocal20Km":  square(5, -100, 1),
+		"latlonCCWorld":            [][][]float64{square(10, -100, 1)},
+		"latlonCaribeRegional":     [][][]float64{square(10, -90, 1)},
+		"latlonCaribeLocal":        square(10, -80, 1),
+		"latlonCaribeLocalInsular": square(15, -80, 1),
+	}
+}
+
+// writeRegions guarda las capas en un archivo temporal y retorna su ruta
+func writeRegions(t *testing.T, layers map[string]interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(layers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "regions.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestValidateRegionFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		change   func(layers map[string]interface{})
+		opts     ValidationOptions
+		errors   int
+		check    string // Chequeo que debe aparecer ("" = ninguno)
+		severity string
+		layer    string
+	}{
+		{
+			name:   "válido",
+			change: func(map[string]interface{}) {},
+		},
+		{
+			name: "Pacífico Local dentro de Pacífico Regional",
+			change: func(layers map[string]interface{}) {
+				layers["latlonPacificoRegional"] = square(-1, -96, 3)
+			},
+		},
+		{
+			name: "Caribe Local dentro de Caribe Regional",
+			change: func(layers map[string]interface{}) {
+				layers["latlonCaribeRegional"] = [][][]float64{square(9, -81, 3)}
+			},
+			errors: 1, check: "overlap", severity: SeverityError, layer: "latlonCaribeRegional",
+		},
+		{
+			name: "capas que se cruzan",
+			change: func(layers map[string]interface{}) {
+				layers["latlonCaribeRegional"] = [][][]float64{square(10.5, -99.5, 1)}
+			},
+			errors: 1, check: "overlap", severity: SeverityError, layer: "latlonCCWorld",
+		},
+		{
+			name: "capas que comparten borde",
+			change: func(layers map[string]interface{}) {
+				layers["latlonCaribeRegional"] = [][][]float64{square(10, -99, 1)}
+			},
+		},
+		{
+			name: "punto incompleto",
+			change: func(layers map[string]interface{}) {
+				ring := square(10, -80, 1)
+				ring[2] = []float64{11}
+				layers["latlonCaribeLocal"] = ring
+			},
+			errors: 1, check: "short_point", severity: SeverityError, layer: "latlonCaribeLocal",
+		},
+		{
+			name: "anillo sin cerrar",
+			change: func(layers map[string]interface{}) {
+				layers["latlonCaribeLocal"] = square(10, -80, 1)[:4]
+			},
+			check: "unclosed_ring", severity: SeverityWarning, layer: "latlonCaribeLocal",
+		},
+		{
+			name: "sentido horario",
+			change: func(layers map[string]interface{}) {
+				ring := square(10, -80, 1)
+				for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+					ring[i], ring[j] = ring[j], ring[i]
+				}
+				layers["latlonCaribeLocal"] = ring
+			},
+			check: "winding", severity: SeverityWarning, layer: "latlonCaribeLocal",
+		},
+		{
+			name: "vértice duplicado",
+			change: func(layers map[string]interface{}) {
+				ring := square(10, -80, 1)
+				layers["latlonCaribeLocal"] = append(ring[:2], ring[1:]...)
+			},
+			check: "duplicate_vertex", severity: SeverityWarning, layer: "latlonCaribeLocal",
+		},
+		{
+			name: "auto-intersección",
+			change: func(layers map[string]interface{}) {
+				layers["latlonCaribeLocal"] = [][]float64{{10, -80}, {12, -79}, {10, -79}, {11, -80}, {10, -80}}
+			},
+			errors: 1, check: "self_intersection", severity: SeverityError, layer: "latlonCaribeLocal",
+		},
+		{
+			name: "menos de 3 puntos",
+			change: func(layers map[string]interface{}) {
+				layers["latlonCaribeLocal"] = [][]float64{{10, -80}, {11, -80}, {10, -80}}
+			},
+			errors: 1, check: "too_few_points", severity: SeverityError, layer: "latlonCaribeLocal",
+		},
+		{
+			name: "área cero",
+			change: func(layers map[string]interface{}) {
+				layers["latlonCaribeLocal"] = [][]float64{{10, -80}, {10.5, -80}, {11, -80}, {10, -80}}
+			},
+			errors: 1, check: "zero_area", severity: SeverityError, layer: "latlonCaribeLocal",
+		},
+		{
+			name: "capa ausente",
+			change: func(layers map[string]interface{}) {
+				delete(layers, "latlonCaribeLocalInsular")
+			},
+			check: "missing_layer", severity: SeverityWarning, layer: "latlonCaribeLocalInsular",
+		},
+		{
+			name: "hueco entre zonas vecinas",
+			change: func(layers map[string]interface{}) {
+				// Caribe Local a ~1 km al norte de Caribe Regional
+				layers["latlonCaribeLocal"] = square(11.01, -90, 1)
+			},
+			opts:  ValidationOptions{GapToleranceKm: 2},
+			check: "coastal_gap", severity: SeverityWarning, layer: "latlonCaribeLocal",
+		},
+		{
+			name: "hueco sin tolerancia configurada",
+			change: func(layers map[string]interface{}) {
+				layers["latlonCaribeLocal"] = square(11.01, -90, 1)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layers := validLayers()
+			tt.change(layers)
+			report, err := ValidateRegionFile(writeRegions(t, layers), tt.opts)
+			if err != nil {
+				t.Fatalf("ValidateRegionFile() error = %v", err)
+			}
+
+			if report.Errors != tt.errors {
+				t.Errorf("Errors = %d, want %d (issues: %+v)", report.Errors, tt.errors, report.Issues)
+			}
+			if tt.check == "" {
+				if len(report.Issues) != 0 {
+					t.Errorf("Issues = %+v, want none", report.Issues)
+				}
+				return
+			}
+			found := false
+			for _, issue := range report.Issues {
+				if issue.Check == tt.check && issue.Severity == tt.severity && issue.Layer == tt.layer {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Issues = %+v, want %s %s on %s", report.Issues, tt.severity, tt.check, tt.layer)
+			}
+		})
+	}
+}
+
+func TestValidateRegionFileVersion(t *testing.T) {
+	path := writeRegions(t, validLayers())
+	report, err := ValidateRegionFile(path, ValidationOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Version != sha256Hex(data) {
+		t.Errorf("Version = %s, want the SHA-256 of the file", report.Version)
+	}
+
+	if _, err := ValidateRegionFile(filepath.Join(t.TempDir(), "missing.json"), ValidationOptions{}); !os.IsNotExist(err) {
+		t.Errorf("ValidateRegionFile() on a missing file = %v, want not exist", err)
+	}
+}
@@ -0,0 +1,124 @@
+package manager
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/events"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// maxChanges es el número máximo de cambios guardados para la sincronización incremental
+const maxChanges = 10000
+
+// Change es una creación, actualización o eliminación de un sismo
+type Change struct {
+	Seq        uint64            `json:"seq"` // Número de secuencia, creciente incluso entre reinicios (ver newSeqBase)
+	Type       events.Type       `json:"type"`
+	ID         string            `json:"id"`
+	Earthquake models.Earthquake `json:"earthquake"` // Versión nueva (la última conocida si se eliminó)
+	Time       time.Time         `json:"-"`          // Momento del cambio
+}
+
+// MarshalJSON formatea el momento del cambio igual que el tiempo del sismo
+func (c Change) MarshalJSON() ([]byte, error) {
+	type Alias Change
+	return json.Marshal(&struct {
+		Time string `json:"time"`
+		*Alias
+	}{
+		Time:  c.Time.Format("2006-01-02 15:04:05"),
+		Alias: (*Alias)(&c),
+	})
+}
+
+// ChangeSet es el resultado de GetChanges
+type ChangeSet struct {
+	Changes []Change `json:"changes"`
+	Cursor  uint64   `json:"cursor"`  // Valor de since para la siguiente consulta
+	HasMore bool     `json:"hasMore"` // Hay más cambios después de Cursor (se alcanzó el límite)
+	Reset   bool     `json:"reset"`   // Faltan cambios: el cliente debe volver a descargar la lista completa
+}
+
+// newSeqBase retorna el número de secuencia inicial de un proceso: los milisegundos
+// desde 1970 por 1000. Así la secuencia supera a la de cualquier ejecución anterior
+// sin guardarla en disco (mientras esa no haya promediado más de un millón de
+// cambios por segundo) y se mantiene bajo 2^53, exacta en los números de JavaScript.
+func newSeqBase(now time.Time) uint64 {
+	return uint64(now.UnixMilli()) * 1000
+}
+
+// publish registra el cambio en el historial y lo publica en el bus con el mismo
+// número de secuencia. El bus recibe los eventos en el orden de la secuencia.
+func (em *EarthquakeManager) publish(eventType events.Type, eq models.Earthquake, previous *models.Earthquake) {
+	em.changeMu.Lock()
+	defer em.changeMu.Unlock()
+
+	em.changeSeq++
+	em.changes = append(em.changes, Change{
+		Seq:        em.changeSeq,
+		Type:       eventType,
+		ID:         eq.ID,
+		Earthquake: eq,
+		Time:       time.Now(),
+	})
+	if len(em.changes) > maxChanges {
+		dropped := len(em.changes) - maxChanges
+		em.changeFloor = em.changes[dropped-1].Seq
+		em.changes = append([]Change(nil), em.changes[dropped:]...)
+	}
+
+	em.events.Publish(em.changeSeq, eventType, eq, previous)
+}
+
+// ChangeSeq retorna el número de secuencia del último cambio
+// Un cliente que descarga la lista completa puede leerlo antes y luego pedir los
+// cambios desde ese punto sin perder ninguno.
+func (em *EarthquakeManager) ChangeSeq() uint64 {
+	em.changeMu.Lock()
+	defer em.changeMu.Unlock()
+	return em.changeSeq
+}
+
+// NextSeq reserva el siguiente número de la secuencia para un mensaje que no es un
+// cambio de sismo (ej: los IDs del stream SSE), así todo comparte una sola secuencia.
+// Deja un salto en la numeración de /api/changes.
+func (em *EarthquakeManager) NextSeq() uint64 {
+	em.changeMu.Lock()
+	defer em.changeMu.Unlock()
+	em.changeSeq++
+	return em.changeSeq
+}
+
+// GetChanges retorna hasta limit cambios posteriores a since (limit <= 0 = sin límite)
+// Si alguno de esos cambios ya no está en el historial (since es anterior a los
+// guardados o de una ejecución anterior del proceso), o since es mayor que la
+// secuencia actual, retorna Reset = true sin cambios y el cursor actual.
+func (em *EarthquakeManager) GetChanges(since uint64, limit int) ChangeSet {
+	em.changeMu.Lock()
+	defer em.changeMu.Unlock()
+
+	if since < em.changeFloor || since > em.changeSeq {
+		return ChangeSet{Changes: []Change{}, Cursor: em.changeSeq, Reset: true}
+	}
+
+	first := sort.Search(len(em.changes), func(i int) bool {
+		return em.changes[i].Seq > since
+	})
+	pending := em.changes[first:]
+	hasMore := limit > 0 && len(pending) > limit
+	if hasMore {
+		pending = pending[:limit]
+	}
+
+	changes := make([]Change, len(pending))
+	copy(changes, pending)
+
+	cursor := since
+	if len(changes) > 0 {
+		cursor = changes[len(changes)-1].Seq
+	}
+
+	return ChangeSet{Changes: changes, Cursor: cursor, HasMore: hasMore}
+}
@@ -0,0 +1,128 @@
+package manager
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+	"github.com/andresgallo/evida_backend_go/internal/sequence"
+	"github.com/andresgallo/evida_backend_go/internal/threat"
+)
+
+// EarthquakeDetail es la vista completa de un sismo para su página de detalle
+type EarthquakeDetail struct {
+	Earthquake     models.Earthquake       `json:"earthquake"`
+	Origins        []Origin                `json:"origins"`   // Este sismo y los reportes del mismo evento en otras fuentes
+	Revisions      []models.Earthquake     `json:"revisions"` // Versiones anteriores (la más antigua primero)
+	Categorization geometry.Categorization `json:"categorization"`
+	Threat         *threat.Rule            `json:"threat,omitempty"`   // Regla de la matriz que decidió el nivel
+	Sequence       *sequence.Sequence      `json:"sequence,omitempty"` // Secuencia a la que pertenece
+}
+
+// Origin es el reporte de un evento por una fuente
+type Origin struct {
+	ID          string    `json:"id"`
+	Source      string    `json:"source"`
+	Magnitude   float64   `json:"magnitude"`
+	Latitude    float64   `json:"latitude"`
+	Longitude   float64   `json:"longitude"`
+	Depth       float64   `json:"depth"`
+	Time        time.Time `json:"-"`
+	URL         string    `json:"url,omitempty"`
+	Preferred   bool      `json:"preferred"`   // El sismo consultado
+	DistanceKm  float64   `json:"distanceKm"`  // Distancia al epicentro del sismo consultado
+	TimeOffsetS float64   `json:"timeOffsetS"` // Diferencia de tiempo de origen en segundos
+}
+
+// MarshalJSON formatea el tiempo de origen igual que el del sismo
+func (o Origin) MarshalJSON() ([]byte, error) {
+	type Alias Origin
+	return json.Marshal(&struct {
+		Time string `json:"time"`
+		*Alias
+	}{
+		Time:  o.Time.Format("2006-01-02 15:04:05"),
+		Alias: (*Alias)(&o),
+	})
+}
+
+// GetDetail retorna la vista completa de un sismo; false si no existe
+func (em *EarthquakeManager) GetDetail(id string) (EarthquakeDetail, bool) {
+	eq, ok := em.index.get(id)
+	if !ok {
+		return EarthquakeDetail{}, false
+	}
+
+	detail := EarthquakeDetail{
+		Earthquake:     eq,
+		Origins:        em.originsOf(eq),
+		Revisions:      em.GetRevisions(id),
+		Categorization: geometry.ExplainCategorization(models.Point{Lat: eq.Latitude, Lon: eq.Longitude}),
+	}
+	if detail.Revisions == nil {
+		detail.Revisions = []models.Earthquake{}
+	}
+
+	if eq.ThreatRule != "" {
+		for _, rule := range threat.Rules() {
+			if rule.ID == eq.ThreatRule {
+				rule := rule
+				detail.Threat = &rule
+				break
+			}
+		}
+	}
+
+	if eq.SequenceID != "" {
+		for _, seq := range em.GetSequences() {
+			if seq.ID == eq.SequenceID {
+				seq := seq
+				detail.Sequence = &seq
+				break
+			}
+		}
+	}
+
+	return detail, true
+}
+
+// originsOf retorna el sismo y los de otras fuentes cercanos en tiempo y espacio,
+// ordenados por diferencia de tiempo (el sismo consultado primero)
+func (em *EarthquakeManager) originsOf(eq models.Earthquake) []Origin {
+	epicenter := models.Point{Lat: eq.Latitude, Lon: eq.Longitude}
+
+	candidates := em.index.collect(indexAll, "", eq.Time.Add(-originWindow), eq.Time.Add(originWindow+time.Nanosecond),
+		func(other *models.Earthquake) bool {
+			return other.ID == eq.ID || sameEvent(&eq, other)
+		})
+
+	origins := make([]Origin, 0, len(candidates))
+	for _, other := range candidates {
+		distance := geometry.Distance(epicenter, models.Point{Lat: other.Latitude, Lon: other.Longitude})
+		origins = append(origins, Origin{
+			ID:          other.ID,
+			Source:      other.Source,
+			Magnitude:   other.Magnitude,
+			Latitude:    other.Latitude,
+			Longitude:   other.Longitude,
+			Depth:       other.Depth,
+			Time:        other.Time,
+			URL:         other.URL,
+			Preferred:   other.ID == eq.ID,
+			DistanceKm:  math.Round(distance*10) / 10,
+			TimeOffsetS: other.Time.Sub(eq.Time).Seconds(),
+		})
+	}
+
+	sort.SliceStable(origins, func(i, j int) bool {
+		if origins[i].Preferred != origins[j].Preferred {
+			return origins[i].Preferred
+		}
+		return math.Abs(origins[i].TimeOffsetS) < math.Abs(origins[j].TimeOffsetS)
+	})
+
+	return origins
+}
@@ -1,67 +1,268 @@
 package manager
 
 import (
-	"sort"
+	"errors"
+	"log"
 	"sync"
 	"time"
 
+	"github.com/andresgallo/evida_backend_go/internal/analytics"
+	"github.com/andresgallo/evida_backend_go/internal/events"
+	"github.com/andresgallo/evida_backend_go/internal/gazetteer"
 	"github.com/andresgallo/evida_backend_go/internal/geometry"
 	"github.com/andresgallo/evida_backend_go/internal/models"
+	"github.com/andresgallo/evida_backend_go/internal/sequence"
+	"github.com/andresgallo/evida_backend_go/internal/store"
+	"github.com/andresgallo/evida_backend_go/internal/threat"
+	"github.com/andresgallo/evida_backend_go/internal/tsunami"
 )
 
-// EarthquakeManager gestiona los sismos en memoria
+const (
+	// nearestTownsCount es el número de poblaciones cercanas que se calculan por sismo
+	nearestTownsCount = 3
+
+	// maxRevisions es el número máximo de versiones anteriores guardadas por sismo
+	maxRevisions = 20
+
+	// defaultFreshWindow es el retraso máximo entre el origen y la recepción de un
+	// sismo para considerarlo nuevo y notificarlo
+	defaultFreshWindow = 1 * time.Hour
+)
+
+// EarthquakeManager gestiona los sismos sobre un almacenamiento (memoria o disco)
 type EarthquakeManager struct {
-	mu          sync.RWMutex
-	earthquakes map[string]models.Earthquake // ID -> Earthquake
-	maxAge      time.Duration                // Tiempo máximo para mantener sismos en memoria
+	store  store.Store      // Almacenamiento de los sismos
+	index  *earthquakeIndex // Índice en memoria para las consultas
+	maxAge time.Duration    // Tiempo máximo para mantener sismos almacenados
 
-	// Canal para notificar nuevos sismos
-	newEarthquakeChan chan models.Earthquake
+	mu          sync.RWMutex
+	revisions   map[string][]models.Earthquake // ID -> versiones anteriores (la más antigua primero)
+	sources     map[string]SourceHealth        // Fuente -> estado de las consultas
+	freshWindow time.Duration                  // Retraso máximo origen -> recepción para notificar
+
+	// Retención: políticas (la primera que aplica), destino de los vencidos (nil = eliminar)
+	// y totales de lo eliminado
+	retention      []RetentionPolicy
+	archive        store.Store
+	retentionStats RetentionStats
+
+	// Bus para publicar sismos creados, actualizados y eliminados
+	events *events.Bus
+
+	// Historial de cambios para la sincronización incremental. La secuencia es la
+	// misma de los eventos del bus; los cambios hasta changeFloor pueden faltar
+	// (descartados o de una ejecución anterior)
+	changeMu    sync.Mutex
+	changeSeq   uint64
+	changeFloor uint64
+	changes     []Change
+
+	// Secuencias calculadas en segundo plano (ver StartSequences); clusterMu
+	// serializa los cálculos y seqMu protege el resultado
+	clusterMu      sync.Mutex
+	seqMu          sync.Mutex
+	sequences      []sequence.Sequence
+	sequencesAt    time.Time
+	sequencesDirty bool
 }
 
-// NewEarthquakeManager crea un nuevo gestor de sismos
-func NewEarthquakeManager(maxAge time.Duration) *EarthquakeManager {
-	return &EarthquakeManager{
-		earthquakes:       make(map[string]models.Earthquake),
-		maxAge:            maxAge,
-		newEarthquakeChan: make(chan models.Earthquake, 100),
+// NewEarthquakeManager crea un nuevo gestor de sismos sobre el almacenamiento dado
+// El índice en memoria se construye con los sismos ya almacenados.
+func NewEarthquakeManager(st store.Store, maxAge time.Duration) *EarthquakeManager {
+	earthquakes, err := st.All()
+	if err != nil {
+		log.Printf("⚠️  Error leyendo sismos almacenados: %v", err)
+	}
+
+	seqBase := newSeqBase(time.Now())
+	em := &EarthquakeManager{
+		store:       st,
+		index:       newEarthquakeIndex(earthquakes),
+		maxAge:      maxAge,
+		revisions:   make(map[string][]models.Earthquake),
+		sources:     make(map[string]SourceHealth),
+		freshWindow: defaultFreshWindow,
+		events:      events.NewBus(),
+		changeSeq:   seqBase,
+		changeFloor: seqBase,
+
+		sequencesDirty: true,
 	}
+	return em
 }
 
-// AddEarthquake agrega un sismo al gestor
-// Retorna true si es un sismo nuevo y categorizado, false si ya existía o no fue categorizado
-func (em *EarthquakeManager) AddEarthquake(eq models.Earthquake) bool {
+// SetFreshWindow cambia el retraso máximo entre el origen y la recepción de un sismo
+// para que se considere nuevo y se notifique
+func (em *EarthquakeManager) SetFreshWindow(window time.Duration) {
 	em.mu.Lock()
 	defer em.mu.Unlock()
+	em.freshWindow = window
+}
+
+// isHistorical indica si un sismo no debe notificarse: su origen es más antiguo que
+// la ventana de sismos nuevos al momento de recibirlo. En la sincronización inicial
+// esto silencia la semana que devuelven las fuentes, pero no los sismos recientes
+// que ocurrieron mientras el proceso estaba detenido.
+func (em *EarthquakeManager) isHistorical(eq models.Earthquake, receivedAt time.Time) bool {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+	return receivedAt.Sub(eq.Time) > em.freshWindow
+}
 
+// AddEarthquake agrega un sismo al gestor
+// Si el sismo ya existía y la fuente cambió sus datos (magnitud, ubicación, tiempo)
+// se actualiza en el almacenamiento.
+// Los sismos no categorizados se guardan con océano y región "Uncategorized" pero no se notifican.
+// Tampoco se notifican los históricos (recibidos tarde). Un histórico deja de serlo si
+// una actualización material (magnitud, ubicación, profundidad, tiempo) llega dentro
+// de la ventana de sismos nuevos, para que las alertas vean esa actualización.
+// Los sismos que la retención ya considera vencidos no se guardan.
+// Retorna true si es un sismo nuevo, false si ya existía
+func (em *EarthquakeManager) AddEarthquake(eq models.Earthquake) bool {
 	// Verificar si ya existe
-	if _, exists := em.earthquakes[eq.ID]; exists {
+	existing, err := em.store.Get(eq.ID)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		log.Printf("⚠️  Error consultando sismo %s: %v", eq.ID, err)
 		return false
 	}
+	isNew := err != nil
+	if !isNew && !sourceChanged(existing, eq) {
+		droppedTotal.WithLabelValues(eq.Source, "duplicate").Inc()
+		return false
+	}
+
+	// Categorizar y enriquecer antes de escribir: la estimación de tsunami
+	// recorre la grilla batimétrica
+	prepare(&eq)
 
-	// Categorizar el sismo
-	geometry.CategorizeEarthquake(&eq)
+	// Las fuentes devuelven días que la retención ya eliminó (ej: SGC 5 días con una
+	// política de 2); guardarlos haría que la limpieza los borre y vuelvan en cada consulta
+	if em.isExpired(eq, time.Now()) {
+		droppedTotal.WithLabelValues(eq.Source, "expired").Inc()
+		return false
+	}
 
-	// Solo agregar y notificar si está categorizado
-	if eq.Oceano == "" || eq.Oceano == "Uncategorized" ||
-		eq.OceanoRegion == "" || eq.OceanoRegion == "Uncategorized" {
-		// No agregar sismos no categorizados
+	if !isNew {
+		eq.IngestedAt = existing.IngestedAt
+		eq.Revision = existing.Revision + 1
+		eq.UpdatedAt = time.Now()
+		eq.Historical = existing.Historical
+		if eq.Historical && materialChange(existing, eq) {
+			eq.Historical = em.isHistorical(eq, eq.UpdatedAt)
+		}
+		if err := em.store.Update(eq); err != nil {
+			log.Printf("⚠️  Error actualizando sismo %s: %v", eq.ID, err)
+			droppedTotal.WithLabelValues(eq.Source, "error").Inc()
+			return false
+		}
+		updatedTotal.WithLabelValues(eq.Source).Inc()
+		// La secuencia se conserva hasta el próximo cálculo en segundo plano
+		if indexed, ok := em.index.get(eq.ID); ok {
+			eq.SequenceID = indexed.SequenceID
+		}
+		em.index.put(eq)
+		em.markSequencesDirty()
+		em.addRevision(existing)
+		em.publish(events.Updated, eq, &existing)
 		return false
 	}
 
-	// Agregar al mapa
-	em.earthquakes[eq.ID] = eq
+	eq.IngestedAt = time.Now()
+	eq.Historical = em.isHistorical(eq, eq.IngestedAt)
 
-	// Notificar mediante el canal (non-blocking)
-	select {
-	case em.newEarthquakeChan <- eq:
-	default:
-		// Si el canal está lleno, no bloqueamos
+	if err := em.store.Insert(eq); err != nil {
+		// ErrExists: otra goroutine lo agregó mientras se categorizaba
+		if !errors.Is(err, store.ErrExists) {
+			log.Printf("⚠️  Error guardando sismo %s: %v", eq.ID, err)
+			droppedTotal.WithLabelValues(eq.Source, "error").Inc()
+		} else {
+			droppedTotal.WithLabelValues(eq.Source, "duplicate").Inc()
+		}
+		return false
 	}
+	newTotal.WithLabelValues(eq.Source).Inc()
+	categorizedTotal.WithLabelValues(eq.Oceano, eq.OceanoRegion).Inc()
+	em.index.put(eq)
+	// La secuencia se asigna en el próximo cálculo en segundo plano (ver StartSequences)
+	em.markSequencesDirty()
+
+	// Los consumidores deciden qué notificar (ver events.Event.Notifiable)
+	em.publish(events.Created, eq, nil)
 
 	return true
 }
 
+// addRevision guarda la versión anterior de un sismo actualizado
+func (em *EarthquakeManager) addRevision(previous models.Earthquake) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	revisions := append(em.revisions[previous.ID], previous)
+	if len(revisions) > maxRevisions {
+		revisions = revisions[len(revisions)-maxRevisions:]
+	}
+	em.revisions[previous.ID] = revisions
+}
+
+// GetRevisions retorna las versiones anteriores de un sismo (la más antigua primero)
+func (em *EarthquakeManager) GetRevisions(id string) []models.Earthquake {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	revisions := make([]models.Earthquake, len(em.revisions[id]))
+	copy(revisions, em.revisions[id])
+	return revisions
+}
+
+// sourceChanged indica si la fuente cambió los datos de origen de un sismo ya almacenado
+func sourceChanged(stored, fetched models.Earthquake) bool {
+	return stored.Magnitude != fetched.Magnitude ||
+		stored.Latitude != fetched.Latitude ||
+		stored.Longitude != fetched.Longitude ||
+		stored.Depth != fetched.Depth ||
+		stored.Location != fetched.Location ||
+		!stored.Time.Equal(fetched.Time)
+}
+
+// materialChange indica si la actualización cambia el origen del sismo y no solo
+// su descripción
+func materialChange(stored, fetched models.Earthquake) bool {
+	return stored.Magnitude != fetched.Magnitude ||
+		stored.Latitude != fetched.Latitude ||
+		stored.Longitude != fetched.Longitude ||
+		stored.Depth != fetched.Depth ||
+		!stored.Time.Equal(fetched.Time)
+}
+
+// prepare categoriza el sismo y agrega la información derivada de su ubicación
+func prepare(eq *models.Earthquake) {
+	geometry.CategorizeEarthquake(eq)
+
+	// Categoría explícita para los que no caen en ninguna zona, así no se
+	// vuelven a categorizar en cada consulta a las fuentes
+	if !eq.IsCategorized() {
+		eq.Oceano = models.Uncategorized
+		eq.OceanoRegion = models.Uncategorized
+	}
+
+	enrich(eq)
+}
+
+// enrich agrega la información derivada de la ubicación de un sismo
+func enrich(eq *models.Earthquake) {
+	// Poblaciones costeras más cercanas (para todas las fuentes, no solo SGC)
+	eq.NearestTowns = gazetteer.NearestTowns(models.Point{Lat: eq.Latitude, Lon: eq.Longitude}, nearestTownsCount)
+
+	// Tiempos de llegada del tsunami y nivel de amenaza (requiere la distancia a la costa)
+	if eq.IsCategorized() {
+		eq.TsunamiETAs = tsunami.EstimateArrivals(*eq)
+
+		assessment := threat.Assess(*eq)
+		eq.ThreatLevel = string(assessment.Level)
+		eq.ThreatRule = assessment.RuleID
+	}
+}
+
 // AddEarthquakes agrega múltiples sismos y retorna los nuevos
 func (em *EarthquakeManager) AddEarthquakes(earthquakes []models.Earthquake) []models.Earthquake {
 	newOnes := make([]models.Earthquake, 0)
@@ -78,114 +279,147 @@ func (em *EarthquakeManager) AddEarthquakes(earthquakes []models.Earthquake) []m
 // GetAll retorna todos los sismos categorizados ordenados por tiempo (más reciente primero)
 // Solo retorna sismos que tienen océano y región válidos (no "Uncategorized")
 func (em *EarthquakeManager) GetAll() []models.Earthquake {
-	em.mu.RLock()
-	defer em.mu.RUnlock()
-
-	earthquakes := make([]models.Earthquake, 0, len(em.earthquakes))
-	for _, eq := range em.earthquakes {
-		// Solo agregar sismos categorizados
-		if eq.Oceano != "" && eq.Oceano != "Uncategorized" &&
-			eq.OceanoRegion != "" && eq.OceanoRegion != "Uncategorized" {
-			earthquakes = append(earthquakes, eq)
-		}
-	}
+	return em.getAll(false)
+}
 
-	// Ordenar por tiempo (más reciente primero)
-	sort.Slice(earthquakes, func(i, j int) bool {
-		return earthquakes[i].Time.After(earthquakes[j].Time)
-	})
+// GetAllIncludingUncategorized retorna todos los sismos, incluidos los no categorizados,
+// ordenados por tiempo (más reciente primero)
+func (em *EarthquakeManager) GetAllIncludingUncategorized() []models.Earthquake {
+	return em.getAll(true)
+}
 
-	return earthquakes
+// getAll retorna los sismos ordenados por tiempo, con o sin los no categorizados
+func (em *EarthquakeManager) getAll(includeUncategorized bool) []models.Earthquake {
+	if includeUncategorized {
+		return em.index.collect(indexAll, "", time.Time{}, time.Time{}, nil)
+	}
+	return em.index.collect(indexAll, "", time.Time{}, time.Time{}, isCategorized)
 }
 
 // GetByOceano retorna sismos filtrados por océano, ordenados por tiempo
 func (em *EarthquakeManager) GetByOceano(oceano string) []models.Earthquake {
-	em.mu.RLock()
-	defer em.mu.RUnlock()
-
-	earthquakes := make([]models.Earthquake, 0)
-	for _, eq := range em.earthquakes {
-		if eq.Oceano == oceano {
-			earthquakes = append(earthquakes, eq)
-		}
-	}
-
-	// Ordenar por tiempo (más reciente primero)
-	sort.Slice(earthquakes, func(i, j int) bool {
-		return earthquakes[i].Time.After(earthquakes[j].Time)
-	})
-
-	return earthquakes
+	return em.index.collect(indexOceano, oceano, time.Time{}, time.Time{}, nil)
 }
 
 // GetByRegion retorna sismos filtrados por región, ordenados por tiempo
 func (em *EarthquakeManager) GetByRegion(region string) []models.Earthquake {
-	em.mu.RLock()
-	defer em.mu.RUnlock()
+	return em.index.collect(indexRegion, region, time.Time{}, time.Time{}, nil)
+}
 
-	earthquakes := make([]models.Earthquake, 0)
-	for _, eq := range em.earthquakes {
-		if eq.OceanoRegion == region {
-			earthquakes = append(earthquakes, eq)
-		}
-	}
+// GetByTimeRange retorna sismos categorizados en el rango [start, end), ordenados por tiempo
+func (em *EarthquakeManager) GetByTimeRange(start, end time.Time) []models.Earthquake {
+	return em.index.collect(indexAll, "", start, end, isCategorized)
+}
 
-	// Ordenar por tiempo (más reciente primero)
-	sort.Slice(earthquakes, func(i, j int) bool {
-		return earthquakes[i].Time.After(earthquakes[j].Time)
+// GetInBoundingBox retorna sismos categorizados dentro de un rectángulo, ordenados por tiempo
+func (em *EarthquakeManager) GetInBoundingBox(box store.BoundingBox) []models.Earthquake {
+	return em.index.collect(indexAll, "", time.Time{}, time.Time{}, func(eq *models.Earthquake) bool {
+		return eq.IsCategorized() && box.Contains(eq.Latitude, eq.Longitude)
 	})
-
-	return earthquakes
 }
 
-// GetByTimeRange retorna sismos en un rango de tiempo
-func (em *EarthquakeManager) GetByTimeRange(start, end time.Time) []models.Earthquake {
+// GetSeismicity retorna los sismos de las últimas horizon horas (incluidos los no
+// categorizados) con un reporte por evento, para medir tasas de sismicidad.
+// Se omiten los sismos que una política de retención elimina antes del horizonte
+// (o de maxAge si es menor): la limpieza los haría faltar en la parte antigua del
+// periodo y las tasas de fondo y reciente no contarían la misma población.
+func (em *EarthquakeManager) GetSeismicity(horizon time.Duration) []models.Earthquake {
 	em.mu.RLock()
-	defer em.mu.RUnlock()
-
-	earthquakes := make([]models.Earthquake, 0)
-	for _, eq := range em.earthquakes {
-		if eq.Time.After(start) && eq.Time.Before(end) {
-			earthquakes = append(earthquakes, eq)
-		}
+	if em.maxAge < horizon {
+		horizon = em.maxAge
 	}
+	em.mu.RUnlock()
 
-	// Ordenar por tiempo (más reciente primero)
-	sort.Slice(earthquakes, func(i, j int) bool {
-		return earthquakes[i].Time.After(earthquakes[j].Time)
+	start := time.Now().Add(-horizon)
+	earthquakes := em.index.collect(indexAll, "", start, time.Time{}, func(eq *models.Earthquake) bool {
+		_, maxAge := em.retentionFor(*eq)
+		return maxAge >= horizon
 	})
+	unique, _ := dedupeOrigins(earthquakes)
+	return unique
+}
+
+// GetAnalytics calcula las estadísticas de los sismos del rango [start, end)
+// Un evento reportado por varias fuentes se cuenta una vez. El rango se limita a los
+// sismos que pueden estar almacenados (desde la retención más larga hasta ahora), así
+// una ventana enorme no genera un resumen por cada día sin datos.
+func (em *EarthquakeManager) GetAnalytics(start, end time.Time, includeUncategorized bool) analytics.Report {
+	now := time.Now()
+	if oldest := now.Add(-em.maxRetention()); start.Before(oldest) {
+		start = oldest
+	}
+	if end.After(now) {
+		end = now
+	}
+	if end.Before(start) {
+		end = start
+	}
 
-	return earthquakes
+	match := isCategorized
+	if includeUncategorized {
+		match = nil
+	}
+	unique, _ := dedupeOrigins(em.index.collect(indexAll, "", start, end, match))
+	return analytics.Compute(unique, start, end)
+}
+
+// isCategorized es la condición de collect para omitir los no categorizados
+func isCategorized(eq *models.Earthquake) bool {
+	return eq.IsCategorized()
 }
 
 // GetCount retorna el número total de sismos categorizados
 func (em *EarthquakeManager) GetCount() int {
-	em.mu.RLock()
-	defer em.mu.RUnlock()
+	return em.index.count(indexAll, "") - em.index.count(indexOceano, models.Uncategorized)
+}
 
-	count := 0
-	for _, eq := range em.earthquakes {
-		if eq.Oceano != "" && eq.Oceano != "Uncategorized" &&
-			eq.OceanoRegion != "" && eq.OceanoRegion != "Uncategorized" {
-			count++
-		}
-	}
-	return count
+// GetUncategorizedCount retorna el número de sismos no categorizados almacenados
+func (em *EarthquakeManager) GetUncategorizedCount() int {
+	return em.index.count(indexOceano, models.Uncategorized)
 }
 
-// CleanOld elimina sismos más antiguos que maxAge
+// CleanOld elimina los sismos que superan la edad máxima de su política de retención
+// (maxAge si ninguna aplica). Si hay un archivo configurado, los copia antes de eliminarlos.
 func (em *EarthquakeManager) CleanOld() int {
-	em.mu.Lock()
-	defer em.mu.Unlock()
+	now := time.Now()
+	candidates := em.index.collect(indexAll, "", time.Time{}, now.Add(-em.minRetention()), nil)
+
+	removed, archived := 0, 0
+	byPolicy := make(map[string]int)
+	for _, eq := range candidates {
+		policy, maxAge := em.retentionFor(eq)
+		if !eq.Time.Before(now.Add(-maxAge)) {
+			continue
+		}
 
-	cutoff := time.Now().Add(-em.maxAge)
-	removed := 0
+		copied, err := em.archiveEarthquake(eq)
+		if err != nil {
+			// Se conserva para reintentar en la próxima limpieza
+			log.Printf("⚠️  Error archivando sismo %s: %v", eq.ID, err)
+			continue
+		}
+		if copied {
+			archived++
+		}
 
-	for id, eq := range em.earthquakes {
-		if eq.Time.Before(cutoff) {
-			delete(em.earthquakes, id)
-			removed++
+		if err := em.store.Delete(eq.ID); err != nil {
+			log.Printf("⚠️  Error eliminando sismo %s: %v", eq.ID, err)
+			continue
 		}
+		em.index.remove(eq.ID)
+		em.publish(events.Deleted, eq, nil)
+		removed++
+		byPolicy[policy]++
+
+		em.mu.Lock()
+		delete(em.revisions, eq.ID)
+		em.mu.Unlock()
+	}
+
+	em.recordRetention(byPolicy, archived)
+	if removed > 0 {
+		em.markSequencesDirty()
+		logRetention(removed, archived, byPolicy)
 	}
 
 	return removed
@@ -196,47 +430,55 @@ func (em *EarthquakeManager) StartCleanup(interval time.Duration) {
 	ticker := time.NewTicker(interval)
 	go func() {
 		for range ticker.C {
-			removed := em.CleanOld()
-			if removed > 0 {
-				// Log
-			}
+			em.CleanOld()
 		}
 	}()
 }
 
-// GetNewEarthquakeChannel retorna el canal para recibir notificaciones de nuevos sismos
-func (em *EarthquakeManager) GetNewEarthquakeChannel() <-chan models.Earthquake {
-	return em.newEarthquakeChan
+// Events retorna el bus donde se publican los cambios en los sismos
+func (em *EarthquakeManager) Events() *events.Bus {
+	return em.events
 }
 
 // GetStats retorna estadísticas de los sismos
 func (em *EarthquakeManager) GetStats() map[string]interface{} {
-	em.mu.RLock()
-	defer em.mu.RUnlock()
+	earthquakes := em.getAll(true)
 
+	// Mismas definiciones que /api/health: total = categorized + uncategorized
 	stats := make(map[string]interface{})
-	stats["total"] = len(em.earthquakes)
+	stats["total"] = len(earthquakes)
+
+	uncategorized := 0
+	for _, eq := range earthquakes {
+		if !eq.IsCategorized() {
+			uncategorized++
+		}
+	}
+	stats["categorized"] = len(earthquakes) - uncategorized
+	stats["uncategorized"] = uncategorized
 
 	// Contar por océano
 	byOceano := make(map[string]int)
-	for _, eq := range em.earthquakes {
+	for _, eq := range earthquakes {
 		byOceano[eq.Oceano]++
 	}
 	stats["by_oceano"] = byOceano
 
 	// Contar por región
 	byRegion := make(map[string]int)
-	for _, eq := range em.earthquakes {
+	for _, eq := range earthquakes {
 		byRegion[eq.OceanoRegion]++
 	}
 	stats["by_region"] = byRegion
 
 	// Contar por fuente
 	bySource := make(map[string]int)
-	for _, eq := range em.earthquakes {
+	for _, eq := range earthquakes {
 		bySource[eq.Source]++
 	}
 	stats["by_source"] = bySource
 
+	stats["retention"] = em.GetRetentionStats()
+
 	return stats
 }
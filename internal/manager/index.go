@@ -0,0 +1,259 @@
+package manager
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// indexField identifica la lista del índice que se recorre en una consulta
+type indexField int
+
+const (
+	indexAll indexField = iota
+	indexOceano
+	indexRegion
+	indexSource
+)
+
+// earthquakeIndex mantiene en memoria los sismos ordenados por tiempo (más reciente
+// primero, desempate por ID) con listas secundarias por océano, región y fuente.
+// Las consultas por rango de tiempo hacen búsqueda binaria: O(log n + k).
+// Las entradas no se modifican después de insertarse: una actualización reemplaza
+// el puntero, así los lectores pueden copiar los valores bajo RLock.
+type earthquakeIndex struct {
+	mu       sync.RWMutex
+	byID     map[string]*models.Earthquake
+	byTime   []*models.Earthquake
+	byOceano map[string][]*models.Earthquake
+	byRegion map[string][]*models.Earthquake
+	bySource map[string][]*models.Earthquake
+}
+
+// newEarthquakeIndex crea un índice con los sismos dados
+func newEarthquakeIndex(earthquakes []models.Earthquake) *earthquakeIndex {
+	idx := &earthquakeIndex{
+		byID:     make(map[string]*models.Earthquake, len(earthquakes)),
+		byTime:   make([]*models.Earthquake, 0, len(earthquakes)),
+		byOceano: make(map[string][]*models.Earthquake),
+		byRegion: make(map[string][]*models.Earthquake),
+		bySource: make(map[string][]*models.Earthquake),
+	}
+
+	// Ordenar una vez y agregar al final evita insertar uno por uno
+	sorted := make([]*models.Earthquake, 0, len(earthquakes))
+	for i := range earthquakes {
+		eq := earthquakes[i]
+		if _, exists := idx.byID[eq.ID]; exists {
+			continue
+		}
+		idx.byID[eq.ID] = &eq
+		sorted = append(sorted, &eq)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return indexLess(sorted[i], sorted[j])
+	})
+
+	for _, eq := range sorted {
+		idx.byTime = append(idx.byTime, eq)
+		idx.byOceano[eq.Oceano] = append(idx.byOceano[eq.Oceano], eq)
+		idx.byRegion[eq.OceanoRegion] = append(idx.byRegion[eq.OceanoRegion], eq)
+		idx.bySource[eq.Source] = append(idx.bySource[eq.Source], eq)
+	}
+
+	return idx
+}
+
+// indexLess es el orden del índice: más reciente primero, desempate por ID
+func indexLess(a, b *models.Earthquake) bool {
+	if !a.Time.Equal(b.Time) {
+		return a.Time.After(b.Time)
+	}
+	return a.ID < b.ID
+}
+
+// put agrega un sismo o reemplaza la versión indexada
+func (idx *earthquakeIndex) put(eq models.Earthquake) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if old, exists := idx.byID[eq.ID]; exists {
+		idx.unlink(old)
+	}
+
+	entry := &eq
+	idx.byID[eq.ID] = entry
+	idx.byTime = insertSorted(idx.byTime, entry)
+	idx.byOceano[eq.Oceano] = insertSorted(idx.byOceano[eq.Oceano], entry)
+	idx.byRegion[eq.OceanoRegion] = insertSorted(idx.byRegion[eq.OceanoRegion], entry)
+	idx.bySource[eq.Source] = insertSorted(idx.bySource[eq.Source], entry)
+}
+
+// get retorna la versión indexada de un sismo
+func (idx *earthquakeIndex) get(id string) (models.Earthquake, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	entry, exists := idx.byID[id]
+	if !exists {
+		return models.Earthquake{}, false
+	}
+	return *entry, true
+}
+
+// setSequences asigna a cada sismo su secuencia (los ausentes en byEvent quedan sin secuencia)
+func (idx *earthquakeIndex) setSequences(byEvent map[string]string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for id, entry := range idx.byID {
+		sequenceID := byEvent[id]
+		if entry.SequenceID == sequenceID {
+			continue
+		}
+		updated := *entry
+		updated.SequenceID = sequenceID
+		idx.replace(entry, &updated)
+	}
+}
+
+// replace cambia una entrada por otra con la misma posición en el orden
+// (mismo ID, tiempo, océano, región y fuente); requiere el candado de escritura
+func (idx *earthquakeIndex) replace(old, updated *models.Earthquake) {
+	idx.byID[old.ID] = updated
+	replaceSorted(idx.byTime, old, updated)
+	replaceSorted(idx.byOceano[old.Oceano], old, updated)
+	replaceSorted(idx.byRegion[old.OceanoRegion], old, updated)
+	replaceSorted(idx.bySource[old.Source], old, updated)
+}
+
+// remove elimina un sismo del índice
+func (idx *earthquakeIndex) remove(id string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if old, exists := idx.byID[id]; exists {
+		idx.unlink(old)
+	}
+}
+
+// unlink quita una entrada de todas las listas (requiere el candado de escritura)
+func (idx *earthquakeIndex) unlink(entry *models.Earthquake) {
+	delete(idx.byID, entry.ID)
+	idx.byTime = removeSorted(idx.byTime, entry)
+	idx.byOceano[entry.Oceano] = removeSorted(idx.byOceano[entry.Oceano], entry)
+	idx.byRegion[entry.OceanoRegion] = removeSorted(idx.byRegion[entry.OceanoRegion], entry)
+	idx.bySource[entry.Source] = removeSorted(idx.bySource[entry.Source], entry)
+
+	// Eliminar las listas vacías para que las claves reflejen los datos actuales
+	if len(idx.byOceano[entry.Oceano]) == 0 {
+		delete(idx.byOceano, entry.Oceano)
+	}
+	if len(idx.byRegion[entry.OceanoRegion]) == 0 {
+		delete(idx.byRegion, entry.OceanoRegion)
+	}
+	if len(idx.bySource[entry.Source]) == 0 {
+		delete(idx.bySource, entry.Source)
+	}
+}
+
+// insertSorted inserta una entrada manteniendo el orden del índice
+func insertSorted(list []*models.Earthquake, entry *models.Earthquake) []*models.Earthquake {
+	i := sort.Search(len(list), func(i int) bool {
+		return !indexLess(list[i], entry)
+	})
+	list = append(list, nil)
+	copy(list[i+1:], list[i:])
+	list[i] = entry
+	return list
+}
+
+// removeSorted quita una entrada de una lista ordenada
+func removeSorted(list []*models.Earthquake, entry *models.Earthquake) []*models.Earthquake {
+	i := sort.Search(len(list), func(i int) bool {
+		return !indexLess(list[i], entry)
+	})
+	if i < len(list) && list[i] == entry {
+		copy(list[i:], list[i+1:])
+		list[len(list)-1] = nil
+		list = list[:len(list)-1]
+	}
+	return list
+}
+
+// replaceSorted cambia una entrada de una lista ordenada por otra equivalente
+func replaceSorted(list []*models.Earthquake, old, updated *models.Earthquake) {
+	i := sort.Search(len(list), func(i int) bool {
+		return !indexLess(list[i], old)
+	})
+	if i < len(list) && list[i] == old {
+		list[i] = updated
+	}
+}
+
+// list retorna la lista del índice para el campo y valor dados (requiere el candado)
+// Las fuentes se comparan sin distinguir mayúsculas.
+func (idx *earthquakeIndex) list(field indexField, value string) []*models.Earthquake {
+	switch field {
+	case indexOceano:
+		return idx.byOceano[value]
+	case indexRegion:
+		return idx.byRegion[value]
+	case indexSource:
+		for source, list := range idx.bySource {
+			if strings.EqualFold(source, value) {
+				return list
+			}
+		}
+		return nil
+	}
+	return idx.byTime
+}
+
+// timeRange retorna la parte de una lista con tiempo en [start, end)
+// Un tiempo cero deja ese extremo abierto.
+func timeRange(list []*models.Earthquake, start, end time.Time) []*models.Earthquake {
+	// La lista está en orden descendente: primero se salta lo posterior a end
+	from := 0
+	if !end.IsZero() {
+		from = sort.Search(len(list), func(i int) bool {
+			return list[i].Time.Before(end)
+		})
+	}
+	to := len(list)
+	if !start.IsZero() {
+		to = sort.Search(len(list), func(i int) bool {
+			return list[i].Time.Before(start)
+		})
+	}
+	if from > to {
+		return nil
+	}
+	return list[from:to]
+}
+
+// collect copia los sismos de una lista del índice en el rango [start, end) que
+// cumplen la condición (nil = todos), más reciente primero
+func (idx *earthquakeIndex) collect(field indexField, value string, start, end time.Time, match func(*models.Earthquake) bool) []models.Earthquake {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	entries := timeRange(idx.list(field, value), start, end)
+	result := make([]models.Earthquake, 0, len(entries))
+	for _, entry := range entries {
+		if match == nil || match(entry) {
+			result = append(result, *entry)
+		}
+	}
+	return result
+}
+
+// count retorna el número de sismos en una lista del índice
+func (idx *earthquakeIndex) count(field indexField, value string) int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.list(field, value))
+}
@@ -0,0 +1,142 @@
+package manager
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+	"github.com/andresgallo/evida_backend_go/internal/store"
+)
+
+// benchEarthquakes es el tamaño del catálogo de los benchmarks (30 días de las tres fuentes)
+const benchEarthquakes = 20000
+
+// benchCatalog genera sismos repartidos en 30 días; la mitad categorizados
+func benchCatalog(now time.Time) map[string]models.Earthquake {
+	rng := rand.New(rand.NewSource(1))
+	regions := []string{"local", "regional", "lejano"}
+	sources := []string{"USGS", "GEOFON", "SGC"}
+
+	catalog := make(map[string]models.Earthquake, benchEarthquakes)
+	for i := 0; i < benchEarthquakes; i++ {
+		eq := models.Earthquake{
+			ID:        fmt.Sprintf("eq%05d", i),
+			Magnitude: 2 + rng.Float64()*5,
+			Latitude:  -10 + rng.Float64()*25,
+			Longitude: -90 + rng.Float64()*25,
+			Depth:     rng.Float64() * 200,
+			Time:      now.Add(-time.Duration(rng.Int63n(int64(30 * 24 * time.Hour)))),
+			Source:    sources[i%len(sources)],
+		}
+		if i%2 == 0 {
+			eq.Oceano = "Pacifico"
+			eq.OceanoRegion = regions[i%len(regions)]
+		} else {
+			eq.Oceano = models.Uncategorized
+			eq.OceanoRegion = models.Uncategorized
+		}
+		catalog[eq.ID] = eq
+	}
+	return catalog
+}
+
+// benchManager crea un gestor con el catálogo en un almacenamiento en memoria
+func benchManager(b *testing.B, catalog map[string]models.Earthquake) *EarthquakeManager {
+	b.Helper()
+	st := store.NewMemoryStore()
+	for _, eq := range catalog {
+		if err := st.Insert(eq); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return NewEarthquakeManager(st, 30*24*time.Hour)
+}
+
+// mapScan es la lectura anterior al índice: recorrer todos los sismos del mapa,
+// filtrar y ordenar por tiempo
+func mapScan(catalog map[string]models.Earthquake, match func(*models.Earthquake) bool) []models.Earthquake {
+	result := make([]models.Earthquake, 0)
+	for _, eq := range catalog {
+		if match(&eq) {
+			result = append(result, eq)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return indexLess(&result[i], &result[j])
+	})
+	return result
+}
+
+func BenchmarkGetAll(b *testing.B) {
+	catalog := benchCatalog(time.Now())
+
+	b.Run("index", func(b *testing.B) {
+		em := benchManager(b, catalog)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			em.GetAll()
+		}
+	})
+
+	b.Run("map_scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			mapScan(catalog, isCategorized)
+		}
+	})
+}
+
+func BenchmarkGetByTimeRange(b *testing.B) {
+	now := time.Now()
+	catalog := benchCatalog(now)
+	start, end := now.Add(-24*time.Hour), now
+
+	b.Run("index", func(b *testing.B) {
+		em := benchManager(b, catalog)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			em.GetByTimeRange(start, end)
+		}
+	})
+
+	b.Run("map_scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			mapScan(catalog, func(eq *models.Earthquake) bool {
+				return eq.IsCategorized() && !eq.Time.Before(start) && eq.Time.Before(end)
+			})
+		}
+	})
+}
+
+func BenchmarkFind(b *testing.B) {
+	now := time.Now()
+	catalog := benchCatalog(now)
+	minMagnitude := 4.0
+	q := Query{
+		MinMagnitude: &minMagnitude,
+		Start:        now.Add(-7 * 24 * time.Hour),
+		Region:       "local",
+		Limit:        100,
+	}
+
+	b.Run("index", func(b *testing.B) {
+		em := benchManager(b, catalog)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := em.Find(q); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("map_scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			matches := mapScan(catalog, q.matches)
+			if len(matches) > q.Limit {
+				matches = matches[:q.Limit]
+			}
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Métricas de consultas a las fuentes y de ingesta de sismos
+var (
+	fetchAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "evida_fetch_attempts_total",
+		Help: "Consultas realizadas a cada fuente.",
+	}, []string{"source"})
+	fetchFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "evida_fetch_failures_total",
+		Help: "Consultas fallidas a cada fuente.",
+	}, []string{"source"})
+	fetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "evida_fetch_duration_seconds",
+		Help: "Duración de las consultas a cada fuente.",
+		// Las fuentes pueden tardar hasta el timeout del cliente HTTP
+		Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"source"})
+
+	ingestedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "evida_earthquakes_ingested_total",
+		Help: "Sismos recibidos de cada fuente.",
+	}, []string{"source"})
+	newTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "evida_earthquakes_new_total",
+		Help: "Sismos nuevos guardados.",
+	}, []string{"source"})
+	updatedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "evida_earthquakes_updated_total",
+		Help: "Sismos existentes actualizados por su fuente.",
+	}, []string{"source"})
+	droppedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "evida_earthquakes_dropped_total",
+		Help: "Sismos recibidos que no se guardaron (duplicate: ya guardados sin cambios, expired: vencidos según la retención, error: fallo del almacenamiento).",
+	}, []string{"source", "reason"})
+	categorizedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "evida_earthquakes_categorized_total",
+		Help: "Resultado de la categorización de los sismos nuevos por zona.",
+	}, []string{"oceano", "region"})
+)
+
+// Métricas que se leen del gestor y del bus en cada consulta a /metrics
+var (
+	storedEarthquakesDesc = prometheus.NewDesc("evida_earthquakes_stored",
+		"Sismos almacenados por el gestor.", []string{"categorized"}, nil)
+	revisionsStoredDesc = prometheus.NewDesc("evida_earthquake_revisions_stored",
+		"Versiones anteriores de sismos guardadas en memoria.", nil, nil)
+
+	subscriberDeliveredDesc = prometheus.NewDesc("evida_event_subscriber_delivered_total",
+		"Eventos entregados a cada suscriptor del bus.", []string{"subscriber"}, nil)
+	subscriberDroppedDesc = prometheus.NewDesc("evida_event_subscriber_dropped_total",
+		"Eventos descartados por tener lleno el buffer del suscriptor.", []string{"subscriber"}, nil)
+	subscriberQueuedDesc = prometheus.NewDesc("evida_event_subscriber_queued",
+		"Eventos pendientes en el buffer de cada suscriptor.", []string{"subscriber"}, nil)
+)
+
+// managerCollector lee el estado del gestor y del bus al consultar las métricas
+type managerCollector struct {
+	em *EarthquakeManager
+}
+
+// RegisterMetrics registra las métricas del estado del gestor (sismos almacenados,
+// revisiones y suscriptores del bus). Se llama una vez por gestor.
+func (em *EarthquakeManager) RegisterMetrics(registerer prometheus.Registerer) error {
+	return registerer.Register(managerCollector{em: em})
+}
+
+// Describe implementa prometheus.Collector
+func (c managerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- storedEarthquakesDesc
+	ch <- revisionsStoredDesc
+	ch <- subscriberDeliveredDesc
+	ch <- subscriberDroppedDesc
+	ch <- subscriberQueuedDesc
+}
+
+// Collect implementa prometheus.Collector
+func (c managerCollector) Collect(ch chan<- prometheus.Metric) {
+	em := c.em
+
+	total := em.index.count(indexAll, "")
+	uncategorized := em.index.count(indexOceano, models.Uncategorized)
+	ch <- prometheus.MustNewConstMetric(storedEarthquakesDesc, prometheus.GaugeValue, float64(total-uncategorized), "true")
+	ch <- prometheus.MustNewConstMetric(storedEarthquakesDesc, prometheus.GaugeValue, float64(uncategorized), "false")
+
+	em.mu.RLock()
+	revisions := 0
+	for _, versions := range em.revisions {
+		revisions += len(versions)
+	}
+	em.mu.RUnlock()
+	ch <- prometheus.MustNewConstMetric(revisionsStoredDesc, prometheus.GaugeValue, float64(revisions))
+
+	for _, sub := range em.events.Stats() {
+		ch <- prometheus.MustNewConstMetric(subscriberDeliveredDesc, prometheus.CounterValue, float64(sub.Delivered), sub.Name)
+		ch <- prometheus.MustNewConstMetric(subscriberDroppedDesc, prometheus.CounterValue, float64(sub.Dropped), sub.Name)
+		ch <- prometheus.MustNewConstMetric(subscriberQueuedDesc, prometheus.GaugeValue, float64(sub.Queued), sub.Name)
+	}
+}
+
+// observeFetch registra la consulta a una fuente en las métricas
+func observeFetch(source string, count int, duration time.Duration, err error) {
+	fetchAttempts.WithLabelValues(source).Inc()
+	fetchDuration.WithLabelValues(source).Observe(duration.Seconds())
+	if err != nil {
+		fetchFailures.WithLabelValues(source).Inc()
+		return
+	}
+	ingestedTotal.WithLabelValues(source).Add(float64(count))
+}
@@ -0,0 +1,74 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+const (
+	// originWindow es la diferencia máxima de tiempo de origen para considerar que
+	// sismos de fuentes distintas son el mismo evento
+	originWindow = 60 * time.Second
+
+	// originMaxDistanceKm es la distancia máxima entre epicentros para el mismo criterio
+	originMaxDistanceKm = 100.0
+)
+
+// sameEvent indica si dos sismos de fuentes distintas son reportes del mismo evento
+// (origen a menos de originWindow y epicentros a menos de originMaxDistanceKm)
+func sameEvent(a, b *models.Earthquake) bool {
+	if a.ID == b.ID || a.Source == b.Source {
+		return false
+	}
+	offset := a.Time.Sub(b.Time)
+	if offset < -originWindow || offset > originWindow {
+		return false
+	}
+	return geometry.Distance(models.Point{Lat: a.Latitude, Lon: a.Longitude},
+		models.Point{Lat: b.Latitude, Lon: b.Longitude}) <= originMaxDistanceKm
+}
+
+// dedupeOrigins deja un reporte por evento: de los sismos que sameEvent considera
+// el mismo evento se conserva el primero en el orden del índice (origen más reciente)
+// y cada evento tiene a lo sumo un reporte por fuente.
+// Los sismos deben venir ordenados por tiempo, más reciente primero (como los
+// retorna el índice). Retorna los conservados, en el mismo orden, y para cada
+// reporte descartado el ID del conservado.
+func dedupeOrigins(earthquakes []models.Earthquake) ([]models.Earthquake, map[string]string) {
+	unique := make([]models.Earthquake, 0, len(earthquakes))
+	duplicateOf := make(map[string]string)
+	sources := make(map[string][]string) // ID conservado -> fuentes del evento
+
+	for _, eq := range earthquakes {
+		kept := -1
+		// Los conservados más recientes que eq.Time + originWindow no pueden coincidir
+		for i := len(unique) - 1; i >= 0 && unique[i].Time.Sub(eq.Time) <= originWindow; i-- {
+			if sameEvent(&unique[i], &eq) && !containsString(sources[unique[i].ID], eq.Source) {
+				kept = i
+				break
+			}
+		}
+		if kept < 0 {
+			unique = append(unique, eq)
+			sources[eq.ID] = []string{eq.Source}
+			continue
+		}
+		id := unique[kept].ID
+		duplicateOf[eq.ID] = id
+		sources[id] = append(sources[id], eq.Source)
+	}
+
+	return unique, duplicateOf
+}
+
+// containsString indica si la lista contiene el valor
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,337 @@
+package manager
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+	"github.com/andresgallo/evida_backend_go/internal/store"
+)
+
+// Valores aceptados para Query.OrderBy (los mismos nombres que usa el servicio FDSN de USGS)
+const (
+	OrderByTime         = "time"          // Más reciente primero (por defecto)
+	OrderByTimeAsc      = "time-asc"      // Más antiguo primero
+	OrderByMagnitude    = "magnitude"     // Mayor magnitud primero
+	OrderByMagnitudeAsc = "magnitude-asc" // Menor magnitud primero
+	OrderByDistance     = "distance"      // Más cercano al centro primero (requiere Center)
+)
+
+// kmPerDegree es la longitud aproximada de un grado de latitud
+const kmPerDegree = 111.2
+
+// ErrInvalidCursor se retorna cuando el cursor de paginación no se puede decodificar
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ErrInvalidOrderBy se retorna cuando Query.OrderBy no es un orden conocido
+var ErrInvalidOrderBy = errors.New("invalid orderby")
+
+// Query describe una consulta de sismos; los campos vacíos no filtran
+type Query struct {
+	MinMagnitude *float64
+	MaxMagnitude *float64
+	MinDepth     *float64
+	MaxDepth     *float64
+
+	Start time.Time // Inclusive
+	End   time.Time // Exclusivo
+
+	Sources     []string
+	BoundingBox *store.BoundingBox
+	Oceano      string
+	Region      string
+
+	// Búsqueda por radio: con Center cada resultado incluye DistanceKm;
+	// MaxRadiusKm > 0 descarta los sismos más lejanos
+	Center      *models.Point
+	MaxRadiusKm float64
+
+	// Por defecto se omiten los no categorizados, salvo que se pidan con
+	// Oceano o Region "Uncategorized"
+	IncludeUncategorized bool
+
+	OrderBy string
+	Limit   int    // 0 = sin límite
+	Offset  int    // Se ignora si hay Cursor
+	Cursor  string // Cursor retornado en QueryResult.NextCursor
+}
+
+// QueryResult es una página de resultados
+type QueryResult struct {
+	Earthquakes []models.Earthquake
+	Total       int    // Total de sismos que cumplen los filtros (sin paginar)
+	NextCursor  string // Vacío si no hay más páginas
+}
+
+// queryCursor es la posición del último sismo retornado
+type queryCursor struct {
+	ID        string  `json:"id"`
+	Time      int64   `json:"t"`
+	Magnitude float64 `json:"m"`
+	Distance  float64 `json:"d,omitempty"`
+}
+
+// Find ejecuta una consulta y retorna la página pedida
+func (em *EarthquakeManager) Find(q Query) (QueryResult, error) {
+	less, err := orderFunc(q.OrderBy)
+	if err != nil {
+		return QueryResult{}, err
+	}
+	if q.OrderBy == OrderByDistance && q.Center == nil {
+		return QueryResult{}, ErrInvalidOrderBy
+	}
+
+	var after *models.Earthquake
+	if q.Cursor != "" {
+		cursor, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return QueryResult{}, err
+		}
+		after = &models.Earthquake{ID: cursor.ID, Time: time.Unix(0, cursor.Time), Magnitude: cursor.Magnitude, DistanceKm: &cursor.Distance}
+	}
+
+	// El índice retorna los candidatos ordenados por tiempo (más reciente primero)
+	field, value := q.indexField()
+	candidates := em.index.collect(field, value, q.Start, q.End, q.matches)
+
+	matches := candidates[:0]
+	for _, eq := range candidates {
+		if q.Center != nil {
+			distance := geometry.Distance(*q.Center, models.Point{Lat: eq.Latitude, Lon: eq.Longitude})
+			if q.MaxRadiusKm > 0 && distance > q.MaxRadiusKm {
+				continue
+			}
+			eq.DistanceKm = &distance
+		}
+		matches = append(matches, eq)
+	}
+
+	// Los candidatos ya vienen en el orden por defecto
+	switch q.OrderBy {
+	case "", OrderByTime:
+	case OrderByTimeAsc:
+		for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+			matches[i], matches[j] = matches[j], matches[i]
+		}
+	default:
+		sort.Slice(matches, func(i, j int) bool {
+			return less(matches[i], matches[j])
+		})
+	}
+
+	result := QueryResult{Total: len(matches)}
+
+	// Posición inicial: después del cursor o en el offset
+	start := 0
+	if after != nil {
+		start = sort.Search(len(matches), func(i int) bool {
+			return less(*after, matches[i])
+		})
+	} else if q.Offset > 0 {
+		start = q.Offset
+	}
+	if start > len(matches) {
+		start = len(matches)
+	}
+
+	end := len(matches)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+
+	result.Earthquakes = matches[start:end]
+	if end < len(matches) && end > start {
+		result.NextCursor = encodeCursor(matches[end-1])
+	}
+
+	return result, nil
+}
+
+// GetWithinRadius retorna los sismos categorizados a menos de radiusKm del
+// centro, del más cercano al más lejano y con la distancia en DistanceKm
+func (em *EarthquakeManager) GetWithinRadius(center models.Point, radiusKm float64) []models.Earthquake {
+	result, err := em.Find(Query{Center: &center, MaxRadiusKm: radiusKm, OrderBy: OrderByDistance})
+	if err != nil {
+		return []models.Earthquake{}
+	}
+	return result.Earthquakes
+}
+
+// indexField elige la lista del índice más selectiva para la consulta
+func (q Query) indexField() (indexField, string) {
+	switch {
+	case q.Oceano != "":
+		return indexOceano, q.Oceano
+	case q.Region != "":
+		return indexRegion, q.Region
+	case len(q.Sources) == 1:
+		return indexSource, q.Sources[0]
+	}
+	return indexAll, ""
+}
+
+// radiusBoundingBox retorna un rectángulo que contiene el círculo de búsqueda
+// No aplica cerca de los polos ni si el círculo cruza el antimeridiano.
+func (q Query) radiusBoundingBox() (store.BoundingBox, bool) {
+	if q.Center == nil || q.MaxRadiusKm <= 0 {
+		return store.BoundingBox{}, false
+	}
+
+	// Margen del 1% para compensar la aproximación esférica
+	deltaLat := q.MaxRadiusKm / kmPerDegree * 1.01
+	box := store.BoundingBox{
+		MinLat: q.Center.Lat - deltaLat,
+		MaxLat: q.Center.Lat + deltaLat,
+	}
+	if box.MinLat <= -89 || box.MaxLat >= 89 {
+		return store.BoundingBox{}, false
+	}
+
+	maxAbsLat := math.Max(math.Abs(box.MinLat), math.Abs(box.MaxLat))
+	deltaLon := deltaLat / math.Cos(maxAbsLat*math.Pi/180)
+	box.MinLon = q.Center.Lon - deltaLon
+	box.MaxLon = q.Center.Lon + deltaLon
+	if box.MinLon < -180 || box.MaxLon > 180 {
+		return store.BoundingBox{}, false
+	}
+
+	return box, true
+}
+
+// matches indica si el sismo cumple todos los filtros de la consulta
+func (q Query) matches(eq *models.Earthquake) bool {
+	includeUncategorized := q.IncludeUncategorized ||
+		q.Oceano == models.Uncategorized || q.Region == models.Uncategorized
+	if !includeUncategorized && !eq.IsCategorized() {
+		return false
+	}
+
+	if q.MinMagnitude != nil && eq.Magnitude < *q.MinMagnitude {
+		return false
+	}
+	if q.MaxMagnitude != nil && eq.Magnitude > *q.MaxMagnitude {
+		return false
+	}
+	if q.MinDepth != nil && eq.Depth < *q.MinDepth {
+		return false
+	}
+	if q.MaxDepth != nil && eq.Depth > *q.MaxDepth {
+		return false
+	}
+	if !q.Start.IsZero() && eq.Time.Before(q.Start) {
+		return false
+	}
+	if !q.End.IsZero() && !eq.Time.Before(q.End) {
+		return false
+	}
+	if q.Oceano != "" && eq.Oceano != q.Oceano {
+		return false
+	}
+	if q.Region != "" && eq.OceanoRegion != q.Region {
+		return false
+	}
+	if q.BoundingBox != nil && !q.BoundingBox.Contains(eq.Latitude, eq.Longitude) {
+		return false
+	}
+	if box, ok := q.radiusBoundingBox(); ok && !box.Contains(eq.Latitude, eq.Longitude) {
+		return false
+	}
+	if len(q.Sources) > 0 && !containsFold(q.Sources, eq.Source) {
+		return false
+	}
+
+	return true
+}
+
+// orderFunc retorna la función de orden para Query.OrderBy
+// El ID desempata para que el orden sea total y el cursor estable.
+func orderFunc(orderBy string) (func(a, b models.Earthquake) bool, error) {
+	switch orderBy {
+	case "", OrderByTime:
+		return func(a, b models.Earthquake) bool {
+			if !a.Time.Equal(b.Time) {
+				return a.Time.After(b.Time)
+			}
+			return a.ID < b.ID
+		}, nil
+	case OrderByTimeAsc:
+		// Inverso exacto del orden por defecto (también en el desempate)
+		return func(a, b models.Earthquake) bool {
+			if !a.Time.Equal(b.Time) {
+				return a.Time.Before(b.Time)
+			}
+			return a.ID > b.ID
+		}, nil
+	case OrderByMagnitude:
+		return func(a, b models.Earthquake) bool {
+			if a.Magnitude != b.Magnitude {
+				return a.Magnitude > b.Magnitude
+			}
+			return a.ID < b.ID
+		}, nil
+	case OrderByDistance:
+		return func(a, b models.Earthquake) bool {
+			da, db := distanceOf(a), distanceOf(b)
+			if da != db {
+				return da < db
+			}
+			return a.ID < b.ID
+		}, nil
+	case OrderByMagnitudeAsc:
+		return func(a, b models.Earthquake) bool {
+			if a.Magnitude != b.Magnitude {
+				return a.Magnitude < b.Magnitude
+			}
+			return a.ID < b.ID
+		}, nil
+	}
+	return nil, ErrInvalidOrderBy
+}
+
+// distanceOf retorna la distancia calculada en la búsqueda por radio (0 si no hay)
+func distanceOf(eq models.Earthquake) float64 {
+	if eq.DistanceKm == nil {
+		return 0
+	}
+	return *eq.DistanceKm
+}
+
+// encodeCursor codifica la posición de un sismo como cursor opaco
+func encodeCursor(eq models.Earthquake) string {
+	data, _ := json.Marshal(queryCursor{
+		ID:        eq.ID,
+		Time:      eq.Time.UnixNano(),
+		Magnitude: eq.Magnitude,
+		Distance:  distanceOf(eq),
+	})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodifica un cursor generado por encodeCursor
+func decodeCursor(value string) (queryCursor, error) {
+	var cursor queryCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, ErrInvalidCursor
+	}
+	return cursor, nil
+}
+
+// containsFold indica si la lista contiene el valor (sin distinguir mayúsculas)
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package manager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+	"github.com/andresgallo/evida_backend_go/internal/store"
+	"github.com/andresgallo/evida_backend_go/internal/threat"
+)
+
+// defaultRetentionPolicy es el ID de la retención por defecto (maxAge)
+const defaultRetentionPolicy = "default"
+
+// RetentionPolicy define cuánto tiempo se guardan los sismos que cumplen sus condiciones
+// Se aplica la primera política que cumple el sismo; si ninguna aplica se usa maxAge.
+// Los mínimos son inclusivos y los máximos exclusivos.
+type RetentionPolicy struct {
+	ID             string   `json:"id"`
+	MaxAgeDays     float64  `json:"maxAgeDays"`
+	MinMagnitude   *float64 `json:"minMagnitude,omitempty"`
+	MaxMagnitude   *float64 `json:"maxMagnitude,omitempty"`
+	Oceanos        []string `json:"oceanos,omitempty"`
+	Regions        []string `json:"regions,omitempty"`
+	MinThreatLevel string   `json:"minThreatLevel,omitempty"`
+}
+
+// MaxAge retorna la edad máxima de la política
+func (p RetentionPolicy) MaxAge() time.Duration {
+	return time.Duration(p.MaxAgeDays * 24 * float64(time.Hour))
+}
+
+// Matches indica si el sismo cumple las condiciones de la política
+func (p RetentionPolicy) Matches(eq models.Earthquake) bool {
+	if p.MinMagnitude != nil && eq.Magnitude < *p.MinMagnitude {
+		return false
+	}
+	if p.MaxMagnitude != nil && eq.Magnitude >= *p.MaxMagnitude {
+		return false
+	}
+	if len(p.Oceanos) > 0 && !containsFold(p.Oceanos, eq.Oceano) {
+		return false
+	}
+	if len(p.Regions) > 0 && !containsFold(p.Regions, eq.OceanoRegion) {
+		return false
+	}
+	if p.MinThreatLevel != "" &&
+		threat.Level(eq.ThreatLevel).Rank() < threat.Level(p.MinThreatLevel).Rank() {
+		return false
+	}
+	return true
+}
+
+// RetentionStats resume lo eliminado por la limpieza desde el arranque
+type RetentionStats struct {
+	LastRun   time.Time      `json:"lastRun"`
+	Removed   int            `json:"removed"`   // Total eliminado del almacenamiento principal
+	Archived  int            `json:"archived"`  // Total copiado al archivo
+	ByPolicy  map[string]int `json:"byPolicy"`  // Eliminados por política
+	LastCount int            `json:"lastCount"` // Eliminados en la última limpieza
+}
+
+// LoadRetentionPolicies carga las políticas de retención desde un archivo JSON
+func LoadRetentionPolicies(filePath string) ([]RetentionPolicy, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var policies []RetentionPolicy
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for i, policy := range policies {
+		if policy.ID == "" {
+			return nil, fmt.Errorf("retention policy %d has no id", i)
+		}
+		if seen[policy.ID] || policy.ID == defaultRetentionPolicy {
+			return nil, fmt.Errorf("duplicate retention policy id %q", policy.ID)
+		}
+		seen[policy.ID] = true
+		if policy.MaxAgeDays <= 0 {
+			return nil, fmt.Errorf("retention policy %q needs a positive maxAgeDays", policy.ID)
+		}
+		if policy.MinThreatLevel != "" && threat.Level(policy.MinThreatLevel).Rank() < 0 {
+			return nil, fmt.Errorf("retention policy %q has invalid minThreatLevel %q", policy.ID, policy.MinThreatLevel)
+		}
+	}
+	return policies, nil
+}
+
+// SetRetentionPolicies reemplaza las políticas de retención
+func (em *EarthquakeManager) SetRetentionPolicies(policies []RetentionPolicy) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.retention = policies
+}
+
+// SetArchive define el almacenamiento donde se copian los sismos vencidos antes de
+// eliminarlos (nil = solo eliminar)
+func (em *EarthquakeManager) SetArchive(archive store.Store) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.archive = archive
+}
+
+// GetRetentionStats retorna lo eliminado por la limpieza desde el arranque
+func (em *EarthquakeManager) GetRetentionStats() RetentionStats {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	stats := em.retentionStats
+	stats.ByPolicy = make(map[string]int, len(em.retentionStats.ByPolicy))
+	for id, count := range em.retentionStats.ByPolicy {
+		stats.ByPolicy[id] = count
+	}
+	return stats
+}
+
+// retentionFor retorna la política que aplica a un sismo y su edad máxima
+func (em *EarthquakeManager) retentionFor(eq models.Earthquake) (string, time.Duration) {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	for _, policy := range em.retention {
+		if policy.Matches(eq) {
+			return policy.ID, policy.MaxAge()
+		}
+	}
+	return defaultRetentionPolicy, em.maxAge
+}
+
+// isExpired indica si un sismo superó la edad máxima de su política
+func (em *EarthquakeManager) isExpired(eq models.Earthquake, now time.Time) bool {
+	_, maxAge := em.retentionFor(eq)
+	return eq.Time.Before(now.Add(-maxAge))
+}
+
+// minRetention retorna la menor edad máxima entre maxAge y las políticas
+// Ningún sismo más reciente que esa edad puede estar vencido.
+func (em *EarthquakeManager) minRetention() time.Duration {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	shortest := em.maxAge
+	for _, policy := range em.retention {
+		if policy.MaxAge() < shortest {
+			shortest = policy.MaxAge()
+		}
+	}
+	return shortest
+}
+
+// maxRetention retorna la mayor edad máxima entre maxAge y las políticas
+// Ningún sismo más antiguo que esa edad sigue almacenado.
+func (em *EarthquakeManager) maxRetention() time.Duration {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	longest := em.maxAge
+	for _, policy := range em.retention {
+		if policy.MaxAge() > longest {
+			longest = policy.MaxAge()
+		}
+	}
+	return longest
+}
+
+// archiveEarthquake copia un sismo vencido al archivo (si hay uno configurado)
+func (em *EarthquakeManager) archiveEarthquake(eq models.Earthquake) (bool, error) {
+	em.mu.RLock()
+	archive := em.archive
+	em.mu.RUnlock()
+
+	if archive == nil {
+		return false, nil
+	}
+
+	err := archive.Insert(eq)
+	if errors.Is(err, store.ErrExists) {
+		err = archive.Update(eq)
+	}
+	return err == nil, err
+}
+
+// recordRetention registra el resultado de una limpieza
+func (em *EarthquakeManager) recordRetention(byPolicy map[string]int, archived int) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	if em.retentionStats.ByPolicy == nil {
+		em.retentionStats.ByPolicy = make(map[string]int)
+	}
+
+	removed := 0
+	for id, count := range byPolicy {
+		em.retentionStats.ByPolicy[id] += count
+		removed += count
+	}
+	em.retentionStats.LastRun = time.Now()
+	em.retentionStats.LastCount = removed
+	em.retentionStats.Removed += removed
+	em.retentionStats.Archived += archived
+}
+
+// formatPolicyCounts formatea los eliminados por política para el log ("default=3, m6-pacifico=1")
+func formatPolicyCounts(byPolicy map[string]int) string {
+	ids := make([]string, 0, len(byPolicy))
+	for id := range byPolicy {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprintf("%s=%d", id, byPolicy[id]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// logRetention escribe en el log el resultado de una limpieza
+func logRetention(removed, archived int, byPolicy map[string]int) {
+	if archived > 0 {
+		log.Printf("🧹 Limpieza: %d sismos vencidos eliminados, %d archivados (%s)", removed, archived, formatPolicyCounts(byPolicy))
+		return
+	}
+	log.Printf("🧹 Limpieza: %d sismos vencidos eliminados (%s)", removed, formatPolicyCounts(byPolicy))
+}
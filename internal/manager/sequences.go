@@ -0,0 +1,83 @@
+package manager
+
+import (
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/sequence"
+)
+
+// sequenceRefreshInterval es la edad máxima de las secuencias calculadas: la tasa
+// y los sismos de las últimas 24 horas dependen de la hora actual
+const sequenceRefreshInterval = 1 * time.Minute
+
+// markSequencesDirty indica que las secuencias deben recalcularse en la próxima pasada
+func (em *EarthquakeManager) markSequencesDirty() {
+	em.seqMu.Lock()
+	defer em.seqMu.Unlock()
+	em.sequencesDirty = true
+}
+
+// refreshSequences recalcula las secuencias si cambiaron los sismos o si el
+// cálculo es más antiguo que sequenceRefreshInterval, y actualiza el SequenceID
+// de cada sismo en el índice. El agrupamiento es O(n²): los lectores siguen
+// viendo el cálculo anterior mientras dura.
+func (em *EarthquakeManager) refreshSequences() {
+	em.clusterMu.Lock()
+	defer em.clusterMu.Unlock()
+
+	now := time.Now()
+	em.seqMu.Lock()
+	if !em.sequencesDirty && now.Sub(em.sequencesAt) < sequenceRefreshInterval {
+		em.seqMu.Unlock()
+		return
+	}
+	// Los cambios que lleguen durante el cálculo lo vuelven a marcar
+	em.sequencesDirty = false
+	em.seqMu.Unlock()
+
+	// Un mismo evento reportado por varias fuentes se agrupa una sola vez; los
+	// reportes descartados toman la secuencia del conservado
+	earthquakes, duplicateOf := dedupeOrigins(em.index.collect(indexAll, "", time.Time{}, time.Time{}, nil))
+	sequences := sequence.Cluster(earthquakes, now)
+
+	byEvent := make(map[string]string)
+	for _, seq := range sequences {
+		for _, id := range seq.EventIDs {
+			byEvent[id] = seq.ID
+		}
+	}
+	for id, kept := range duplicateOf {
+		if sequenceID, ok := byEvent[kept]; ok {
+			byEvent[id] = sequenceID
+		}
+	}
+	em.index.setSequences(byEvent)
+
+	em.seqMu.Lock()
+	em.sequences = sequences
+	em.sequencesAt = now
+	em.seqMu.Unlock()
+}
+
+// StartSequences inicia una goroutine que recalcula las secuencias periódicamente
+// (solo si cambiaron los sismos o venció sequenceRefreshInterval). Las lecturas
+// usan el último cálculo.
+func (em *EarthquakeManager) StartSequences(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		em.refreshSequences()
+		for range ticker.C {
+			em.refreshSequences()
+		}
+	}()
+}
+
+// GetSequences retorna las secuencias del último cálculo (la más reciente primero)
+func (em *EarthquakeManager) GetSequences() []sequence.Sequence {
+	em.seqMu.Lock()
+	defer em.seqMu.Unlock()
+
+	sequences := make([]sequence.Sequence, len(em.sequences))
+	copy(sequences, em.sequences)
+	return sequences
+}
@@ -0,0 +1,147 @@
+package manager
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+	"github.com/andresgallo/evida_backend_go/internal/store"
+)
+
+// snapshotVersion se incrementa cuando cambia el formato del snapshot
+const snapshotVersion = 1
+
+// snapshot es el estado del gestor que se guarda en disco
+type snapshot struct {
+	Version     int
+	CreatedAt   time.Time
+	Earthquakes []models.Earthquake
+	Revisions   map[string][]models.Earthquake
+	Sources     map[string]SourceHealth
+}
+
+// SaveSnapshot guarda el estado del gestor (sismos, revisiones y salud de las fuentes)
+// en un archivo gob comprimido con gzip. La escritura es atómica: se escribe un archivo
+// temporal y se renombra.
+func (em *EarthquakeManager) SaveSnapshot(path string) error {
+	earthquakes, err := em.store.All()
+	if err != nil {
+		return fmt.Errorf("error reading earthquakes for snapshot: %w", err)
+	}
+
+	em.mu.RLock()
+	snap := snapshot{
+		Version:     snapshotVersion,
+		CreatedAt:   time.Now(),
+		Earthquakes: earthquakes,
+		Revisions:   make(map[string][]models.Earthquake, len(em.revisions)),
+		Sources:     make(map[string]SourceHealth, len(em.sources)),
+	}
+	for id, revisions := range em.revisions {
+		snap.Revisions[id] = revisions
+	}
+	for source, health := range em.sources {
+		snap.Sources[source] = health
+	}
+	em.mu.RUnlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	gz := gzip.NewWriter(tmp)
+	if err := gob.NewEncoder(gz).Encode(snap); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error encoding snapshot: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error compressing snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing snapshot: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot restaura el estado guardado por SaveSnapshot sin generar notificaciones
+// Los sismos que ya están en el almacenamiento o vencidos por su política de retención se omiten.
+// Retorna el número de sismos restaurados; si el archivo no existe el error cumple
+// errors.Is(err, os.ErrNotExist).
+func (em *EarthquakeManager) LoadSnapshot(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return 0, fmt.Errorf("error decompressing snapshot: %w", err)
+	}
+	defer gz.Close()
+
+	var snap snapshot
+	if err := gob.NewDecoder(gz).Decode(&snap); err != nil {
+		return 0, fmt.Errorf("error decoding snapshot: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return 0, fmt.Errorf("unsupported snapshot version %d", snap.Version)
+	}
+
+	now := time.Now()
+	restored := 0
+	for _, eq := range snap.Earthquakes {
+		if em.isExpired(eq, now) {
+			continue
+		}
+		if err := em.store.Insert(eq); err != nil {
+			if !errors.Is(err, store.ErrExists) {
+				log.Printf("⚠️  Error restaurando sismo %s: %v", eq.ID, err)
+			}
+			continue
+		}
+		em.index.put(eq)
+		restored++
+	}
+
+	if restored > 0 {
+		em.markSequencesDirty()
+	}
+
+	em.mu.Lock()
+	for id, revisions := range snap.Revisions {
+		if _, exists := em.revisions[id]; !exists {
+			em.revisions[id] = revisions
+		}
+	}
+	for source, health := range snap.Sources {
+		if _, exists := em.sources[source]; !exists {
+			em.sources[source] = health
+		}
+	}
+	em.mu.Unlock()
+
+	return restored, nil
+}
+
+// StartSnapshots inicia una goroutine que guarda un snapshot periódicamente
+func (em *EarthquakeManager) StartSnapshots(path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		for range ticker.C {
+			if err := em.SaveSnapshot(path); err != nil {
+				log.Printf("⚠️  Error guardando snapshot: %v", err)
+			}
+		}
+	}()
+}
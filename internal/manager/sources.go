@@ -0,0 +1,61 @@
+package manager
+
+import (
+	"sort"
+	"time"
+)
+
+// SourceHealth describe el estado de las consultas a una fuente de datos
+type SourceHealth struct {
+	Source              string    `json:"source"`
+	LastAttempt         time.Time `json:"lastAttempt"`
+	LastSuccess         time.Time `json:"lastSuccess"`
+	LastError           string    `json:"lastError,omitempty"`
+	LastCount           int       `json:"lastCount"` // Sismos retornados en la última consulta exitosa
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	TotalFetches        int       `json:"totalFetches"`
+	TotalFailures       int       `json:"totalFailures"`
+}
+
+// RecordFetch registra el resultado y la duración de una consulta a una fuente
+func (em *EarthquakeManager) RecordFetch(source string, count int, duration time.Duration, err error) {
+	observeFetch(source, count, duration, err)
+
+	em.mu.Lock()
+	defer em.mu.Unlock()
+
+	health := em.sources[source]
+	health.Source = source
+	health.LastAttempt = time.Now()
+	health.TotalFetches++
+
+	if err != nil {
+		health.LastError = err.Error()
+		health.ConsecutiveFailures++
+		health.TotalFailures++
+	} else {
+		health.LastSuccess = health.LastAttempt
+		health.LastError = ""
+		health.LastCount = count
+		health.ConsecutiveFailures = 0
+	}
+
+	em.sources[source] = health
+}
+
+// GetSourceHealth retorna el estado de todas las fuentes ordenado por nombre
+func (em *EarthquakeManager) GetSourceHealth() []SourceHealth {
+	em.mu.RLock()
+	defer em.mu.RUnlock()
+
+	sources := make([]SourceHealth, 0, len(em.sources))
+	for _, health := range em.sources {
+		sources = append(sources, health)
+	}
+
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Source < sources[j].Source
+	})
+
+	return sources
+}
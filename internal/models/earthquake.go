@@ -1,7 +1,10 @@
 package models
 
 import (
+	"bytes"
+	"encoding/gob"
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -18,18 +21,127 @@ type Earthquake struct {
 	Oceano       string    `json:"oceano,omitempty"`       // Pacifico, Caribe
 	OceanoRegion string    `json:"oceanoRegion,omitempty"` // local, regional, lejano
 	URL          string    `json:"url,omitempty"`
-	CloserTowns  string    `json:"closerTowns,omitempty"`  // Pueblos cercanos (SGC)
+	CloserTowns  string    `json:"closerTowns,omitempty"` // Pueblos cercanos (SGC)
+
+	CoastDistanceKm *float64 `json:"coastDistanceKm,omitempty"` // Distancia a la costa más cercana
+	CoastSegment    string   `json:"coastSegment,omitempty"`    // Nombre de esa línea de costa
+
+	NearestTowns []TownDistance `json:"nearestTowns,omitempty"` // Poblaciones costeras más cercanas
+	TsunamiETAs  []TsunamiETA   `json:"tsunamiETAs,omitempty"`  // Llegada estimada del tsunami
+
+	ThreatLevel string `json:"threatLevel,omitempty"` // information, advisory, watch, warning
+	ThreatRule  string `json:"threatRule,omitempty"`  // Regla de la matriz que decidió el nivel
+
+	SequenceID string `json:"sequenceId,omitempty"` // Secuencia (principal, réplicas y premonitores) a la que pertenece
+
+	Revision  int       `json:"revision,omitempty"` // Número de actualizaciones recibidas de la fuente
+	UpdatedAt time.Time `json:"-"`                  // Momento de la última actualización
+
+	IngestedAt time.Time `json:"-"`                    // Momento en que el backend recibió el sismo
+	Historical bool      `json:"historical,omitempty"` // Recibido más tarde que la ventana de sismos nuevos (no se notifica)
+
+	DistanceKm *float64 `json:"distanceKm,omitempty"` // Distancia al centro de una búsqueda por radio (no se almacena)
+}
+
+// TownDistance describe la posición de un sismo respecto a una población
+type TownDistance struct {
+	Name        string  `json:"name"`
+	Department  string  `json:"department"`
+	DistanceKm  float64 `json:"distanceKm"`
+	Bearing     float64 `json:"bearing"`     // Rumbo desde la población hacia el epicentro (grados)
+	Direction   string  `json:"direction"`   // Punto cardinal (N, NNE, ..., NNW)
+	Description string  `json:"description"` // Ej: "35 km SW of Tumaco"
+}
+
+// Uncategorized es el océano y la región de los sismos fuera de todas las zonas
+const Uncategorized = "Uncategorized"
+
+// IsCategorized indica si el sismo tiene océano y región válidos
+func (e Earthquake) IsCategorized() bool {
+	return e.Oceano != "" && e.Oceano != Uncategorized &&
+		e.OceanoRegion != "" && e.OceanoRegion != Uncategorized
 }
 
 // MarshalJSON personaliza la serialización del Earthquake para formatear el tiempo
 func (e Earthquake) MarshalJSON() ([]byte, error) {
 	type Alias Earthquake
 	return json.Marshal(&struct {
-		Time string `json:"time"`
+		Time       string `json:"time"`
+		UpdatedAt  string `json:"updatedAt,omitempty"`
+		IngestedAt string `json:"ingestedAt,omitempty"`
+		*Alias
+	}{
+		Time:       e.Time.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  formatOptionalTime(e.UpdatedAt),
+		IngestedAt: formatOptionalTime(e.IngestedAt),
+		Alias:      (*Alias)(&e),
+	})
+}
+
+// earthquakeGob es la forma de Earthquake en gob
+// gob omite los valores cero aunque estén detrás de un puntero, así que una distancia
+// de 0 km volvería como nil; los indicadores *Set conservan si el puntero existía.
+type earthquakeGob struct {
+	Fields             earthquakeFields
+	CoastDistanceKmSet bool
+	DistanceKmSet      bool
+}
+
+// earthquakeFields tiene los campos de Earthquake sin sus métodos
+type earthquakeFields Earthquake
+
+// GobEncode codifica el sismo conservando las distancias de 0 km
+func (e Earthquake) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(earthquakeGob{
+		Fields:             earthquakeFields(e),
+		CoastDistanceKmSet: e.CoastDistanceKm != nil,
+		DistanceKmSet:      e.DistanceKm != nil,
+	})
+	return buf.Bytes(), err
+}
+
+// GobDecode decodifica un sismo codificado con GobEncode
+func (e *Earthquake) GobDecode(data []byte) error {
+	var decoded earthquakeGob
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+		return err
+	}
+	*e = Earthquake(decoded.Fields)
+	if decoded.CoastDistanceKmSet && e.CoastDistanceKm == nil {
+		e.CoastDistanceKm = new(float64)
+	}
+	if decoded.DistanceKmSet && e.DistanceKm == nil {
+		e.DistanceKm = new(float64)
+	}
+	return nil
+}
+
+// formatOptionalTime formatea un tiempo opcional (cadena vacía si es cero)
+func formatOptionalTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05")
+}
+
+// TsunamiETA es el tiempo estimado de llegada de un tsunami a un punto de pronóstico
+type TsunamiETA struct {
+	Point         string    `json:"point"`
+	TravelMinutes float64   `json:"travelMinutes"`
+	ArrivalTime   time.Time `json:"-"`
+	DistanceKm    float64   `json:"distanceKm"` // Distancia en línea recta desde el epicentro
+}
+
+// MarshalJSON formatea la hora de llegada igual que el tiempo del sismo
+func (t TsunamiETA) MarshalJSON() ([]byte, error) {
+	type Alias TsunamiETA
+	return json.Marshal(&struct {
+		ArrivalTime string `json:"arrivalTime"`
 		*Alias
 	}{
-		Time:  e.Time.Format("2006-01-02 15:04:05"),
-		Alias: (*Alias)(&e),
+		ArrivalTime: t.ArrivalTime.Format("2006-01-02 15:04:05"),
+		Alias:       (*Alias)(&t),
 	})
 }
 
@@ -40,15 +152,17 @@ type Point struct {
 }
 
 // UnmarshalJSON personaliza la deserialización para aceptar arrays [lat, lon]
+// Retorna error si el array tiene menos de dos elementos
 func (p *Point) UnmarshalJSON(data []byte) error {
 	var arr []float64
 	if err := json.Unmarshal(data, &arr); err != nil {
 		return err
 	}
-	if len(arr) >= 2 {
-		p.Lat = arr[0]
-		p.Lon = arr[1]
+	if len(arr) < 2 {
+		return fmt.Errorf("invalid point %s: expected [lat, lon]", string(data))
 	}
+	p.Lat = arr[0]
+	p.Lon = arr[1]
 	return nil
 }
 
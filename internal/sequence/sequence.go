@@ -0,0 +1,173 @@
+// Package sequence agrupa sismos en secuencias (sismo principal más réplicas y
+// premonitores) con las ventanas espacio-temporales de Gardner y Knopoff (1974).
+//
+// Los sismos se recorren de mayor a menor magnitud. Cada sismo que aún no
+// pertenece a una secuencia abre una ventana de distancia L(M) y tiempo T(M)
+// alrededor de sí mismo; los sismos menores sin secuencia dentro de la ventana
+// pasan a ser réplicas (después) o premonitores (antes). Los sismos aislados no
+// forman secuencia.
+package sequence
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// IDPrefix antecede al ID del sismo principal en el ID de la secuencia
+const IDPrefix = "seq-"
+
+// Sequence es un sismo principal con sus réplicas y premonitores
+type Sequence struct {
+	ID            string            `json:"id"`
+	Mainshock     models.Earthquake `json:"mainshock"`
+	Count         int               `json:"count"` // Incluye el sismo principal
+	Foreshocks    int               `json:"foreshocks"`
+	Aftershocks   int               `json:"aftershocks"`
+	Start         time.Time         `json:"-"`             // Primer sismo de la secuencia
+	End           time.Time         `json:"-"`             // Último sismo de la secuencia
+	RatePerDay    float64           `json:"ratePerDay"`    // Sismos por día desde el primero
+	EventsLast24h int               `json:"eventsLast24h"` // Sismos en las últimas 24 horas
+	WindowKm      float64           `json:"windowKm"`      // Radio de la ventana L(M)
+	WindowDays    float64           `json:"windowDays"`    // Duración de la ventana T(M)
+	EventIDs      []string          `json:"eventIds"`      // Más antiguo primero
+}
+
+// MarshalJSON formatea el inicio y el fin igual que el tiempo de los sismos
+func (s Sequence) MarshalJSON() ([]byte, error) {
+	type Alias Sequence
+	return json.Marshal(&struct {
+		Start string `json:"start"`
+		End   string `json:"end"`
+		*Alias
+	}{
+		Start: s.Start.Format("2006-01-02 15:04:05"),
+		End:   s.End.Format("2006-01-02 15:04:05"),
+		Alias: (*Alias)(&s),
+	})
+}
+
+// Window retorna la ventana de Gardner-Knopoff para una magnitud:
+// L = 10^(0.1238·M + 0.983) km y T = 10^(0.032·M + 2.7389) días para M >= 6.5,
+// 10^(0.5409·M - 0.547) días para M < 6.5
+func Window(magnitude float64) (distanceKm float64, duration time.Duration) {
+	distanceKm = math.Pow(10, 0.1238*magnitude+0.983)
+
+	var days float64
+	if magnitude >= 6.5 {
+		days = math.Pow(10, 0.032*magnitude+2.7389)
+	} else {
+		days = math.Pow(10, 0.5409*magnitude-0.547)
+	}
+	return distanceKm, time.Duration(days * 24 * float64(time.Hour))
+}
+
+// Cluster agrupa los sismos en secuencias, ordenadas por el tiempo del sismo
+// principal (más reciente primero). now se usa para la tasa y las últimas 24 horas.
+func Cluster(earthquakes []models.Earthquake, now time.Time) []Sequence {
+	// Orden por tiempo para buscar las ventanas con búsqueda binaria
+	byTime := make([]int, len(earthquakes))
+	for i := range byTime {
+		byTime[i] = i
+	}
+	sort.Slice(byTime, func(a, b int) bool {
+		return earthquakes[byTime[a]].Time.Before(earthquakes[byTime[b]].Time)
+	})
+
+	// Orden de procesamiento: mayor magnitud primero, el más antiguo en empates
+	byMagnitude := make([]int, len(earthquakes))
+	copy(byMagnitude, byTime)
+	sort.SliceStable(byMagnitude, func(a, b int) bool {
+		return earthquakes[byMagnitude[a]].Magnitude > earthquakes[byMagnitude[b]].Magnitude
+	})
+
+	processed := make([]bool, len(earthquakes))
+	var sequences []Sequence
+
+	for _, main := range byMagnitude {
+		if processed[main] {
+			continue
+		}
+		processed[main] = true
+
+		mainshock := earthquakes[main]
+		distanceKm, duration := Window(mainshock.Magnitude)
+		epicenter := models.Point{Lat: mainshock.Latitude, Lon: mainshock.Longitude}
+
+		from := sort.Search(len(byTime), func(i int) bool {
+			return !earthquakes[byTime[i]].Time.Before(mainshock.Time.Add(-duration))
+		})
+		members := []int{}
+		for _, i := range byTime[from:] {
+			eq := earthquakes[i]
+			if eq.Time.After(mainshock.Time.Add(duration)) {
+				break
+			}
+			if processed[i] {
+				continue
+			}
+			if geometry.Distance(epicenter, models.Point{Lat: eq.Latitude, Lon: eq.Longitude}) <= distanceKm {
+				members = append(members, i)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+
+		for _, i := range members {
+			processed[i] = true
+		}
+		sequences = append(sequences, newSequence(earthquakes, main, members, distanceKm, duration, now))
+	}
+
+	sort.Slice(sequences, func(i, j int) bool {
+		return sequences[i].Mainshock.Time.After(sequences[j].Mainshock.Time)
+	})
+	return sequences
+}
+
+// newSequence construye una secuencia a partir del sismo principal y sus miembros
+func newSequence(earthquakes []models.Earthquake, main int, members []int, distanceKm float64, duration time.Duration, now time.Time) Sequence {
+	mainshock := earthquakes[main]
+	all := append([]int{main}, members...)
+	sort.Slice(all, func(a, b int) bool {
+		return earthquakes[all[a]].Time.Before(earthquakes[all[b]].Time)
+	})
+
+	seq := Sequence{
+		ID:         IDPrefix + mainshock.ID,
+		Mainshock:  mainshock,
+		Count:      len(all),
+		Start:      earthquakes[all[0]].Time,
+		End:        earthquakes[all[len(all)-1]].Time,
+		WindowKm:   distanceKm,
+		WindowDays: duration.Hours() / 24,
+		EventIDs:   make([]string, 0, len(all)),
+	}
+	seq.Mainshock.SequenceID = seq.ID
+
+	for _, i := range all {
+		eq := earthquakes[i]
+		seq.EventIDs = append(seq.EventIDs, eq.ID)
+		switch {
+		case i == main:
+		case eq.Time.Before(mainshock.Time):
+			seq.Foreshocks++
+		default:
+			seq.Aftershocks++
+		}
+		if now.Sub(eq.Time) <= 24*time.Hour {
+			seq.EventsLast24h++
+		}
+	}
+
+	// Tasa desde el primer sismo; al menos una hora para no exagerar secuencias recién iniciadas
+	days := math.Max(now.Sub(seq.Start).Hours(), 1) / 24
+	seq.RatePerDay = float64(seq.Count) / days
+
+	return seq
+}
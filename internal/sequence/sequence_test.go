@@ -0,0 +1,152 @@
+package sequence
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// t0 es el tiempo del sismo principal de los casos
+var t0 = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// quake crea un sismo a km kilómetros al norte de (0, 0) y days días de t0
+func quake(id string, magnitude, km, days float64) models.Earthquake {
+	return models.Earthquake{
+		ID:        id,
+		Magnitude: magnitude,
+		Latitude:  km / (6371.0 * math.Pi / 180),
+		Time:      t0.Add(time.Duration(days * 24 * float64(time.Hour))),
+	}
+}
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		magnitude float64
+		km        float64
+		days      float64
+	}{
+		{5.0, 40.0, 143.7},
+		{6.0, 53.2, 499.3},
+		{6.5, 61.3, 884.9},
+		{7.0, 70.7, 918.1},
+	}
+
+	for _, tt := range tests {
+		km, duration := Window(tt.magnitude)
+		days := duration.Hours() / 24
+		if math.Abs(km-tt.km) > 0.1 || math.Abs(days-tt.days) > 0.1 {
+			t.Errorf("Window(%.1f) = %.1f km, %.1f days, want %.1f km, %.1f days", tt.magnitude, km, days, tt.km, tt.days)
+		}
+	}
+}
+
+func TestCluster(t *testing.T) {
+	tests := []struct {
+		name        string
+		earthquakes []models.Earthquake
+		want        [][]string // IDs de cada secuencia, la más reciente primero
+		foreshocks  []int
+		aftershocks []int
+	}{
+		{
+			name: "réplicas dentro y fuera de la ventana",
+			earthquakes: []models.Earthquake{
+				quake("main", 6.0, 0, 0),
+				quake("after", 4.0, 10, 1),
+				quake("fore", 4.5, 20, -2),
+				quake("far", 4.0, 100, 1),     // Fuera de L(6) = 53 km
+				quake("late", 4.0, 10, 600),   // Fuera de T(6) = 500 días
+				quake("early", 4.0, 10, -600), // Antes de la ventana
+			},
+			want:        [][]string{{"fore", "main", "after"}},
+			foreshocks:  []int{1},
+			aftershocks: []int{1},
+		},
+		{
+			name: "sismo aislado",
+			earthquakes: []models.Earthquake{
+				quake("alone", 6.0, 0, 0),
+			},
+			want: nil,
+		},
+		{
+			name: "sin encadenar ventanas",
+			earthquakes: []models.Earthquake{
+				quake("main", 6.0, 0, 0),
+				quake("after", 5.0, 40, 10),   // Réplica de main
+				quake("chained", 4.5, 70, 11), // Dentro de L(5) de after, fuera de L(6) de main
+				quake("tail", 3.0, 72, 12),    // Réplica de chained
+			},
+			want:        [][]string{{"chained", "tail"}, {"main", "after"}},
+			foreshocks:  []int{0, 0},
+			aftershocks: []int{1, 1},
+		},
+		{
+			name: "dos secuencias separadas",
+			earthquakes: []models.Earthquake{
+				quake("north", 5.5, 1000, 5),
+				quake("north-after", 4.0, 1010, 6),
+				quake("south", 6.0, 0, 0),
+				quake("south-after", 4.0, 5, 1),
+			},
+			want:        [][]string{{"north", "north-after"}, {"south", "south-after"}},
+			foreshocks:  []int{0, 0},
+			aftershocks: []int{1, 1},
+		},
+		{
+			name: "misma magnitud: el más antiguo es el principal",
+			earthquakes: []models.Earthquake{
+				quake("second", 5.0, 5, 1),
+				quake("first", 5.0, 0, 0),
+			},
+			want:        [][]string{{"first", "second"}},
+			foreshocks:  []int{0},
+			aftershocks: []int{1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sequences := Cluster(tt.earthquakes, t0.Add(30*24*time.Hour))
+			var got [][]string
+			for _, seq := range sequences {
+				got = append(got, seq.EventIDs)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Cluster() = %v, want %v", got, tt.want)
+			}
+			for i, seq := range sequences {
+				if seq.Count != len(seq.EventIDs) || seq.Foreshocks != tt.foreshocks[i] || seq.Aftershocks != tt.aftershocks[i] {
+					t.Errorf("sequence %s = count %d, %d foreshocks, %d aftershocks, want %d, %d, %d",
+						seq.ID, seq.Count, seq.Foreshocks, seq.Aftershocks, len(seq.EventIDs), tt.foreshocks[i], tt.aftershocks[i])
+				}
+				if seq.ID != IDPrefix+seq.Mainshock.ID || seq.Mainshock.SequenceID != seq.ID {
+					t.Errorf("sequence %s has mainshock %s (sequence %q)", seq.ID, seq.Mainshock.ID, seq.Mainshock.SequenceID)
+				}
+			}
+		})
+	}
+}
+
+func TestClusterRates(t *testing.T) {
+	now := t0.Add(2 * 24 * time.Hour)
+	sequences := Cluster([]models.Earthquake{
+		quake("main", 6.0, 0, 0),
+		quake("a", 4.0, 5, 1.5),
+		quake("b", 4.0, 5, 1.9),
+	}, now)
+
+	if len(sequences) != 1 {
+		t.Fatalf("Cluster() = %d sequences, want 1", len(sequences))
+	}
+	seq := sequences[0]
+	if seq.EventsLast24h != 2 {
+		t.Errorf("EventsLast24h = %d, want 2", seq.EventsLast24h)
+	}
+	if math.Abs(seq.RatePerDay-1.5) > 1e-9 {
+		t.Errorf("RatePerDay = %v, want 1.5", seq.RatePerDay)
+	}
+}
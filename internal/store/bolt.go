@@ -0,0 +1,288 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// Sismos codificados con gob, por ID
+	earthquakesBucket = []byte("earthquakes")
+
+	// Índice por tiempo de origen: tiempo (12 bytes) + ID
+	byTimeBucket = []byte("by_time")
+
+	// Índice espacial por celda de 1°: celda (4 bytes) + ID
+	byCellBucket = []byte("by_cell")
+)
+
+// maxBoundingBoxCells es el número máximo de celdas que se recorren en una consulta espacial;
+// para rectángulos más grandes es más barato recorrer todos los sismos
+const maxBoundingBoxCells = 1000
+
+// BoltStore guarda los sismos en un archivo bbolt con índices por tiempo y por celda
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore abre (o crea) el archivo de base de datos en la ruta indicada
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening bolt store %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{earthquakesBucket, byTimeBucket, byCellBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating bolt buckets: %w", err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+// Insert agrega un sismo nuevo
+func (s *BoltStore) Insert(eq models.Earthquake) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(earthquakesBucket).Get([]byte(eq.ID)) != nil {
+			return ErrExists
+		}
+		return putEarthquake(tx, eq)
+	})
+}
+
+// Update reemplaza un sismo existente y actualiza sus índices
+func (s *BoltStore) Update(eq models.Earthquake) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		old, err := getEarthquake(tx, eq.ID)
+		if err != nil {
+			return err
+		}
+		if err := deleteIndexes(tx, old); err != nil {
+			return err
+		}
+		return putEarthquake(tx, eq)
+	})
+}
+
+// Delete elimina un sismo y sus índices
+func (s *BoltStore) Delete(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		old, err := getEarthquake(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := deleteIndexes(tx, old); err != nil {
+			return err
+		}
+		return tx.Bucket(earthquakesBucket).Delete([]byte(id))
+	})
+}
+
+// Get retorna un sismo por ID
+func (s *BoltStore) Get(id string) (models.Earthquake, error) {
+	var eq models.Earthquake
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		eq, err = getEarthquake(tx, id)
+		return err
+	})
+	return eq, err
+}
+
+// All retorna todos los sismos
+func (s *BoltStore) All() ([]models.Earthquake, error) {
+	earthquakes := make([]models.Earthquake, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(earthquakesBucket).ForEach(func(_, value []byte) error {
+			eq, err := decodeEarthquake(value)
+			if err != nil {
+				return err
+			}
+			earthquakes = append(earthquakes, eq)
+			return nil
+		})
+	})
+	return earthquakes, err
+}
+
+// ByTimeRange retorna los sismos con start <= tiempo < end usando el índice por tiempo
+func (s *BoltStore) ByTimeRange(start, end time.Time) ([]models.Earthquake, error) {
+	earthquakes := make([]models.Earthquake, 0)
+	endKey := timeKey(end)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(byTimeBucket).Cursor()
+		for key, _ := c.Seek(timeKey(start)); key != nil && bytes.Compare(key[:len(endKey)], endKey) < 0; key, _ = c.Next() {
+			eq, err := getEarthquake(tx, string(key[len(endKey):]))
+			if err != nil {
+				return err
+			}
+			earthquakes = append(earthquakes, eq)
+		}
+		return nil
+	})
+	return earthquakes, err
+}
+
+// InBoundingBox retorna los sismos dentro del rectángulo usando el índice por celda
+func (s *BoltStore) InBoundingBox(box BoundingBox) ([]models.Earthquake, error) {
+	minRow, minCol := cellCoords(box.MinLat, box.MinLon)
+	maxRow, maxCol := cellCoords(box.MaxLat, box.MaxLon)
+
+	if minRow > maxRow || minCol > maxCol {
+		return []models.Earthquake{}, nil
+	}
+
+	// Rectángulos muy grandes: recorrer todo es más barato que visitar cada celda
+	if (maxRow-minRow+1)*(maxCol-minCol+1) > maxBoundingBoxCells {
+		all, err := s.All()
+		if err != nil {
+			return nil, err
+		}
+		earthquakes := make([]models.Earthquake, 0)
+		for _, eq := range all {
+			if box.Contains(eq.Latitude, eq.Longitude) {
+				earthquakes = append(earthquakes, eq)
+			}
+		}
+		return earthquakes, nil
+	}
+
+	earthquakes := make([]models.Earthquake, 0)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(byCellBucket).Cursor()
+		for row := minRow; row <= maxRow; row++ {
+			for col := minCol; col <= maxCol; col++ {
+				prefix := cellPrefix(row, col)
+				for key, _ := c.Seek(prefix); key != nil && bytes.HasPrefix(key, prefix); key, _ = c.Next() {
+					eq, err := getEarthquake(tx, string(key[len(prefix):]))
+					if err != nil {
+						return err
+					}
+					if box.Contains(eq.Latitude, eq.Longitude) {
+						earthquakes = append(earthquakes, eq)
+					}
+				}
+			}
+		}
+		return nil
+	})
+	return earthquakes, err
+}
+
+// Count retorna el número de sismos almacenados
+func (s *BoltStore) Count() (int, error) {
+	count := 0
+	err := s.db.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket(earthquakesBucket).Stats().KeyN
+		return nil
+	})
+	return count, err
+}
+
+// Close cierra el archivo de base de datos
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// putEarthquake guarda el sismo y sus entradas de índice
+func putEarthquake(tx *bolt.Tx, eq models.Earthquake) error {
+	value, err := encodeEarthquake(eq)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(earthquakesBucket).Put([]byte(eq.ID), value); err != nil {
+		return err
+	}
+	if err := tx.Bucket(byTimeBucket).Put(append(timeKey(eq.Time), eq.ID...), nil); err != nil {
+		return err
+	}
+	row, col := cellCoords(eq.Latitude, eq.Longitude)
+	return tx.Bucket(byCellBucket).Put(append(cellPrefix(row, col), eq.ID...), nil)
+}
+
+// deleteIndexes elimina las entradas de índice de un sismo
+func deleteIndexes(tx *bolt.Tx, eq models.Earthquake) error {
+	if err := tx.Bucket(byTimeBucket).Delete(append(timeKey(eq.Time), eq.ID...)); err != nil {
+		return err
+	}
+	row, col := cellCoords(eq.Latitude, eq.Longitude)
+	return tx.Bucket(byCellBucket).Delete(append(cellPrefix(row, col), eq.ID...))
+}
+
+// getEarthquake lee y decodifica un sismo dentro de una transacción
+func getEarthquake(tx *bolt.Tx, id string) (models.Earthquake, error) {
+	value := tx.Bucket(earthquakesBucket).Get([]byte(id))
+	if value == nil {
+		return models.Earthquake{}, ErrNotFound
+	}
+	return decodeEarthquake(value)
+}
+
+// encodeEarthquake codifica un sismo con gob (conserva el tiempo completo, a diferencia del JSON de la API)
+func encodeEarthquake(eq models.Earthquake) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(eq); err != nil {
+		return nil, fmt.Errorf("error encoding earthquake %s: %w", eq.ID, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeEarthquake decodifica un sismo codificado con gob
+func decodeEarthquake(value []byte) (models.Earthquake, error) {
+	var eq models.Earthquake
+	if err := gob.NewDecoder(bytes.NewReader(value)).Decode(&eq); err != nil {
+		return eq, fmt.Errorf("error decoding earthquake: %w", err)
+	}
+	return eq, nil
+}
+
+// timeKey codifica un tiempo en 12 bytes que se ordenan cronológicamente
+// (segundos con el bit de signo invertido + nanosegundos)
+func timeKey(t time.Time) []byte {
+	key := make([]byte, 12)
+	binary.BigEndian.PutUint64(key, uint64(t.Unix())^(1<<63))
+	binary.BigEndian.PutUint32(key[8:], uint32(t.Nanosecond()))
+	return key
+}
+
+// cellCoords retorna la fila y columna de la celda de 1° que contiene al punto
+func cellCoords(lat, lon float64) (int, int) {
+	row := int(math.Floor(lat)) + 90
+	col := int(math.Floor(lon)) + 180
+	return clamp(row, 0, 179), clamp(col, 0, 359)
+}
+
+// cellPrefix codifica una celda en 4 bytes
+func cellPrefix(row, col int) []byte {
+	key := make([]byte, 4)
+	binary.BigEndian.PutUint16(key, uint16(row))
+	binary.BigEndian.PutUint16(key[2:], uint16(col))
+	return key
+}
+
+// clamp limita v al rango [min, max]
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// MemoryStore guarda los sismos en un mapa en memoria
+// Se pierde al reiniciar; útil para pruebas y despliegues efímeros.
+type MemoryStore struct {
+	mu          sync.RWMutex
+	earthquakes map[string]models.Earthquake // ID -> Earthquake
+}
+
+// NewMemoryStore crea un almacenamiento en memoria vacío
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		earthquakes: make(map[string]models.Earthquake),
+	}
+}
+
+// Insert agrega un sismo nuevo
+func (s *MemoryStore) Insert(eq models.Earthquake) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.earthquakes[eq.ID]; exists {
+		return ErrExists
+	}
+	s.earthquakes[eq.ID] = eq
+	return nil
+}
+
+// Update reemplaza un sismo existente
+func (s *MemoryStore) Update(eq models.Earthquake) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.earthquakes[eq.ID]; !exists {
+		return ErrNotFound
+	}
+	s.earthquakes[eq.ID] = eq
+	return nil
+}
+
+// Delete elimina un sismo
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.earthquakes[id]; !exists {
+		return ErrNotFound
+	}
+	delete(s.earthquakes, id)
+	return nil
+}
+
+// Get retorna un sismo por ID
+func (s *MemoryStore) Get(id string) (models.Earthquake, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	eq, exists := s.earthquakes[id]
+	if !exists {
+		return models.Earthquake{}, ErrNotFound
+	}
+	return eq, nil
+}
+
+// All retorna todos los sismos
+func (s *MemoryStore) All() ([]models.Earthquake, error) {
+	return s.filter(func(models.Earthquake) bool { return true }), nil
+}
+
+// ByTimeRange retorna los sismos con start <= tiempo < end
+func (s *MemoryStore) ByTimeRange(start, end time.Time) ([]models.Earthquake, error) {
+	return s.filter(func(eq models.Earthquake) bool {
+		return !eq.Time.Before(start) && eq.Time.Before(end)
+	}), nil
+}
+
+// InBoundingBox retorna los sismos dentro del rectángulo
+func (s *MemoryStore) InBoundingBox(box BoundingBox) ([]models.Earthquake, error) {
+	return s.filter(func(eq models.Earthquake) bool {
+		return box.Contains(eq.Latitude, eq.Longitude)
+	}), nil
+}
+
+// Count retorna el número de sismos almacenados
+func (s *MemoryStore) Count() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.earthquakes), nil
+}
+
+// Close no hace nada en el almacenamiento en memoria
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// filter retorna los sismos que cumplen la condición
+func (s *MemoryStore) filter(match func(models.Earthquake) bool) []models.Earthquake {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	earthquakes := make([]models.Earthquake, 0)
+	for _, eq := range s.earthquakes {
+		if match(eq) {
+			earthquakes = append(earthquakes, eq)
+		}
+	}
+	return earthquakes
+}
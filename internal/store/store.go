@@ -0,0 +1,60 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+var (
+	// ErrNotFound se retorna cuando el sismo no existe en el almacenamiento
+	ErrNotFound = errors.New("earthquake not found")
+
+	// ErrExists se retorna al insertar un sismo con un ID ya existente
+	ErrExists = errors.New("earthquake already exists")
+)
+
+// BoundingBox es un rectángulo geográfico en grados
+type BoundingBox struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// Contains indica si el punto está dentro del rectángulo (bordes incluidos)
+func (b BoundingBox) Contains(lat, lon float64) bool {
+	return lat >= b.MinLat && lat <= b.MaxLat && lon >= b.MinLon && lon <= b.MaxLon
+}
+
+// Store es la interfaz que deben implementar los almacenamientos de sismos
+// Las consultas retornan los sismos sin un orden garantizado.
+type Store interface {
+	// Insert agrega un sismo nuevo; retorna ErrExists si el ID ya existe
+	Insert(eq models.Earthquake) error
+
+	// Update reemplaza un sismo existente; retorna ErrNotFound si no existe
+	Update(eq models.Earthquake) error
+
+	// Delete elimina un sismo; retorna ErrNotFound si no existe
+	Delete(id string) error
+
+	// Get retorna un sismo por ID; retorna ErrNotFound si no existe
+	Get(id string) (models.Earthquake, error)
+
+	// All retorna todos los sismos
+	All() ([]models.Earthquake, error)
+
+	// ByTimeRange retorna los sismos con start <= tiempo < end
+	ByTimeRange(start, end time.Time) ([]models.Earthquake, error)
+
+	// InBoundingBox retorna los sismos cuyo epicentro está dentro del rectángulo
+	InBoundingBox(box BoundingBox) ([]models.Earthquake, error)
+
+	// Count retorna el número de sismos almacenados
+	Count() (int, error)
+
+	// Close libera los recursos del almacenamiento
+	Close() error
+}
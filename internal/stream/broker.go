@@ -0,0 +1,153 @@
+// Package stream difunde los mensajes de notificación como Server-Sent Events
+//
+// Cada mensaje recibe un ID creciente y se guarda en un historial acotado; un cliente
+// que se reconecta con Last-Event-ID recibe lo que se perdió mientras estuvo desconectado.
+// Los IDs salen de una secuencia externa (la de cambios del gestor) para que sigan
+// creciendo después de un reinicio y un ID anterior nunca se confunda con uno nuevo.
+package stream
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"sync"
+)
+
+// Message es un mensaje ya codificado para enviarse como evento SSE
+type Message struct {
+	ID   uint64
+	Type string
+	Data []byte // {"type": ..., "data": ...}, el mismo formato del WebSocket
+}
+
+// envelope es el formato de los mensajes, compartido con el WebSocket
+type envelope struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+}
+
+// Client es un cliente SSE conectado
+type Client struct {
+	messages chan Message
+}
+
+// Messages retorna el canal de mensajes del cliente; se cierra si el cliente se atrasa
+func (c *Client) Messages() <-chan Message {
+	return c.messages
+}
+
+// Broker mantiene el historial de mensajes y los clientes SSE conectados
+type Broker struct {
+	mu          sync.Mutex
+	nextID      func() uint64 // Fuente de IDs crecientes (pueden tener saltos)
+	lastID      uint64
+	floor       uint64    // Los mensajes con ID <= floor ya no están en el historial
+	history     []Message // Últimos mensajes, IDs crecientes terminando en lastID
+	historySize int
+	clientSize  int
+	clients     map[*Client]bool
+}
+
+// NewBroker crea un broker que guarda los últimos historySize mensajes para reanudar
+// conexiones y deja hasta clientBuffer mensajes pendientes por cliente
+// nextID entrega los IDs de los mensajes; debe ser creciente incluso entre reinicios.
+func NewBroker(historySize, clientBuffer int, nextID func() uint64) *Broker {
+	connectedClients.Set(0)
+
+	// Los IDs anteriores al arranque son de otra ejecución y no se pueden reanudar
+	start := nextID()
+	return &Broker{
+		nextID:      nextID,
+		lastID:      start,
+		floor:       start,
+		historySize: historySize,
+		clientSize:  clientBuffer,
+		clients:     make(map[*Client]bool),
+	}
+}
+
+// Broadcast envía un mensaje del tipo dado a todos los clientes y lo agrega al historial
+// Los clientes con el buffer lleno se desconectan (se reconectan con Last-Event-ID).
+func (b *Broker) Broadcast(messageType string, data interface{}) {
+	encoded, err := json.Marshal(envelope{Type: messageType, Data: data})
+	if err != nil {
+		log.Printf("Error marshaling %s stream message: %v", messageType, err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID = b.nextID()
+	message := Message{ID: b.lastID, Type: messageType, Data: encoded}
+
+	b.history = append(b.history, message)
+	if len(b.history) > b.historySize {
+		dropped := len(b.history) - b.historySize
+		b.floor = b.history[dropped-1].ID
+		b.history = b.history[dropped:]
+	}
+
+	for client := range b.clients {
+		select {
+		case client.messages <- message:
+		default:
+			close(client.messages)
+			delete(b.clients, client)
+			slowClientDisconnects.Inc()
+		}
+	}
+	connectedClients.Set(float64(len(b.clients)))
+}
+
+// Subscribe conecta un cliente nuevo
+// Si resume es true, retorna los mensajes posteriores a lastEventID; si alguno ya salió
+// del historial (o el ID es de antes de un reinicio), retorna resync = true y el cliente
+// debe volver a consultar la API REST.
+func (b *Broker) Subscribe(lastEventID uint64, resume bool) (client *Client, backlog []Message, resync bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if resume {
+		if lastEventID > b.lastID || lastEventID < b.floor {
+			resync = true
+		} else {
+			first := sort.Search(len(b.history), func(i int) bool {
+				return b.history[i].ID > lastEventID
+			})
+			backlog = append(backlog, b.history[first:]...)
+		}
+	}
+
+	client = &Client{messages: make(chan Message, b.clientSize)}
+	b.clients[client] = true
+	connectedClients.Set(float64(len(b.clients)))
+
+	return client, backlog, resync
+}
+
+// Unsubscribe desconecta un cliente
+func (b *Broker) Unsubscribe(client *Client) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.clients[client] {
+		delete(b.clients, client)
+		close(client.messages)
+	}
+	connectedClients.Set(float64(len(b.clients)))
+}
+
+// LastID retorna el ID del último mensaje enviado
+func (b *Broker) LastID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastID
+}
+
+// ClientCount retorna el número de clientes conectados
+func (b *Broker) ClientCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.clients)
+}
@@ -0,0 +1,18 @@
+package stream
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Métricas de los clientes SSE
+var (
+	connectedClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "evida_sse_clients",
+		Help: "Clientes SSE conectados a /api/stream.",
+	})
+	slowClientDisconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "evida_sse_slow_client_disconnects_total",
+		Help: "Clientes SSE desconectados por no leer los mensajes a tiempo (buffer lleno).",
+	})
+)
@@ -0,0 +1,202 @@
+// Package threat asigna un nivel de amenaza de tsunami a cada sismo categorizado
+//
+// La evaluación recorre una matriz de decisión (una lista de reglas en orden) y
+// aplica la primera regla que cumple el sismo. La matriz por defecto sigue los
+// criterios de los mensajes del PTWC: los sismos profundos o menores de M6.5 solo
+// generan información, y el nivel sube con la magnitud y la cercanía a Colombia
+// (región local, regional o lejano). Se puede reemplazar con Configure.
+package threat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// Level es un nivel de amenaza de tsunami
+type Level string
+
+const (
+	Information Level = "information" // Sin amenaza de tsunami
+	Advisory    Level = "advisory"    // Corrientes fuertes y oleaje peligroso cerca de la costa
+	Watch       Level = "watch"       // Tsunami posible, en evaluación
+	Warning     Level = "warning"     // Tsunami probable con inundación costera
+)
+
+// Rank retorna el orden del nivel (0 para information, -1 si no es válido)
+func (l Level) Rank() int {
+	switch l {
+	case Information:
+		return 0
+	case Advisory:
+		return 1
+	case Watch:
+		return 2
+	case Warning:
+		return 3
+	}
+	return -1
+}
+
+// Rule es una fila de la matriz de decisión; los campos vacíos no restringen
+// Los rangos son inclusivos en el mínimo y exclusivos en el máximo.
+type Rule struct {
+	ID                 string   `json:"id"`
+	Level              Level    `json:"level"`
+	Description        string   `json:"description,omitempty"`
+	MinMagnitude       *float64 `json:"minMagnitude,omitempty"`
+	MaxMagnitude       *float64 `json:"maxMagnitude,omitempty"`
+	MinDepth           *float64 `json:"minDepth,omitempty"`
+	MaxDepth           *float64 `json:"maxDepth,omitempty"`
+	Oceanos            []string `json:"oceanos,omitempty"`
+	Regions            []string `json:"regions,omitempty"`
+	MaxCoastDistanceKm *float64 `json:"maxCoastDistanceKm,omitempty"`
+}
+
+// Matches indica si el sismo cumple todas las condiciones de la regla
+func (r Rule) Matches(eq models.Earthquake) bool {
+	if r.MinMagnitude != nil && eq.Magnitude < *r.MinMagnitude {
+		return false
+	}
+	if r.MaxMagnitude != nil && eq.Magnitude >= *r.MaxMagnitude {
+		return false
+	}
+	if r.MinDepth != nil && eq.Depth < *r.MinDepth {
+		return false
+	}
+	if r.MaxDepth != nil && eq.Depth >= *r.MaxDepth {
+		return false
+	}
+	if len(r.Oceanos) > 0 && !containsFold(r.Oceanos, eq.Oceano) {
+		return false
+	}
+	if len(r.Regions) > 0 && !containsFold(r.Regions, eq.OceanoRegion) {
+		return false
+	}
+	if r.MaxCoastDistanceKm != nil &&
+		(eq.CoastDistanceKm == nil || *eq.CoastDistanceKm > *r.MaxCoastDistanceKm) {
+		return false
+	}
+	return true
+}
+
+// Assessment es el resultado de evaluar un sismo
+type Assessment struct {
+	Level  Level
+	RuleID string // Regla que decidió el nivel (vacío si no aplicó ninguna)
+}
+
+// ptr retorna un puntero al valor (para escribir la tabla de reglas)
+func ptr(v float64) *float64 { return &v }
+
+// DefaultRules es la matriz de decisión por defecto, evaluada en orden
+var DefaultRules = []Rule{
+	{ID: "deep", Level: Information, MinDepth: ptr(100),
+		Description: "Sismo profundo (>= 100 km): sin amenaza de tsunami"},
+	{ID: "minor", Level: Information, MaxMagnitude: ptr(6.5),
+		Description: "Magnitud menor a 6.5: sin amenaza de tsunami"},
+	{ID: "basin-wide", Level: Warning, MinMagnitude: ptr(7.9),
+		Description: "M >= 7.9: amenaza en toda la cuenca"},
+	{ID: "great-near", Level: Warning, MinMagnitude: ptr(7.6), Regions: []string{"local", "regional"},
+		Description: "M >= 7.6 a menos de ~1000 km"},
+	{ID: "great-far", Level: Watch, MinMagnitude: ptr(7.6),
+		Description: "M >= 7.6 lejano"},
+	{ID: "major-local", Level: Warning, MinMagnitude: ptr(7.1), Regions: []string{"local"},
+		Description: "M >= 7.1 local"},
+	{ID: "major-coast", Level: Watch, MinMagnitude: ptr(7.1), MaxCoastDistanceKm: ptr(300),
+		Description: "M >= 7.1 a menos de 300 km de la costa"},
+	{ID: "major-regional", Level: Watch, MinMagnitude: ptr(7.1), Regions: []string{"regional"},
+		Description: "M >= 7.1 regional"},
+	{ID: "major-far", Level: Advisory, MinMagnitude: ptr(7.1),
+		Description: "M >= 7.1 lejano"},
+	{ID: "strong-local", Level: Watch, Regions: []string{"local"},
+		Description: "M 6.5-7.0 local: tsunami local posible"},
+	{ID: "strong-regional", Level: Advisory, Regions: []string{"regional"},
+		Description: "M 6.5-7.0 regional"},
+	{ID: "strong-far", Level: Information,
+		Description: "M 6.5-7.0 lejano: sin amenaza para Colombia"},
+}
+
+var (
+	mu    sync.RWMutex
+	rules = DefaultRules
+)
+
+// LoadRules carga una matriz de decisión desde un archivo JSON
+func LoadRules(filePath string) ([]Rule, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var loaded []Rule
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return nil, err
+	}
+	if err := Validate(loaded); err != nil {
+		return nil, err
+	}
+	return loaded, nil
+}
+
+// Validate verifica que las reglas tengan ID único y un nivel válido
+func Validate(candidates []Rule) error {
+	seen := make(map[string]bool)
+	for i, rule := range candidates {
+		if rule.ID == "" {
+			return fmt.Errorf("rule %d has no id", i)
+		}
+		if seen[rule.ID] {
+			return fmt.Errorf("duplicate rule id %q", rule.ID)
+		}
+		seen[rule.ID] = true
+		if rule.Level.Rank() < 0 {
+			return fmt.Errorf("rule %q has invalid level %q", rule.ID, rule.Level)
+		}
+	}
+	return nil
+}
+
+// Configure reemplaza la matriz de decisión usada por Assess
+func Configure(newRules []Rule) {
+	mu.Lock()
+	defer mu.Unlock()
+	rules = newRules
+}
+
+// Rules retorna la matriz de decisión configurada
+func Rules() []Rule {
+	mu.RLock()
+	defer mu.RUnlock()
+	return rules
+}
+
+// Assess evalúa un sismo con la matriz configurada
+func Assess(eq models.Earthquake) Assessment {
+	return Evaluate(eq, Rules())
+}
+
+// Evaluate evalúa un sismo con la matriz dada: aplica la primera regla que
+// cumple. Si ninguna aplica el nivel es information.
+func Evaluate(eq models.Earthquake, table []Rule) Assessment {
+	for _, rule := range table {
+		if rule.Matches(eq) {
+			return Assessment{Level: rule.Level, RuleID: rule.ID}
+		}
+	}
+	return Assessment{Level: Information}
+}
+
+// containsFold indica si la lista contiene el valor (sin distinguir mayúsculas)
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
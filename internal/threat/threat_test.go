@@ -0,0 +1,104 @@
+package threat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+func TestEvaluateDefaultRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		magnitude float64
+		depth     float64
+		region    string
+		coastKm   *float64 // nil = sin distancia a la costa
+		level     Level
+		ruleID    string
+	}{
+		// Profundidad: el mínimo de "deep" es inclusivo
+		{"profundo", 8.0, 100, "local", nil, Information, "deep"},
+		{"casi profundo", 8.0, 99.9, "local", nil, Warning, "basin-wide"},
+
+		// M6.5: el máximo de "minor" es exclusivo
+		{"M6.4 local", 6.4, 10, "local", nil, Information, "minor"},
+		{"M6.5 local", 6.5, 10, "local", nil, Watch, "strong-local"},
+		{"M6.5 regional", 6.5, 10, "regional", nil, Advisory, "strong-regional"},
+		{"M6.5 lejano", 6.5, 10, "lejano", nil, Information, "strong-far"},
+		{"M7.0 local", 7.0, 10, "local", nil, Watch, "strong-local"},
+
+		// M7.1
+		{"M7.1 local", 7.1, 10, "local", nil, Warning, "major-local"},
+		{"M7.1 regional sin distancia", 7.1, 10, "regional", nil, Watch, "major-regional"},
+		{"M7.1 regional cerca de la costa", 7.1, 10, "regional", ptr(250), Watch, "major-coast"},
+		{"M7.1 lejano sin distancia", 7.1, 10, "lejano", nil, Advisory, "major-far"},
+		{"M7.1 lejano a 300 km", 7.1, 10, "lejano", ptr(300), Watch, "major-coast"},
+		{"M7.1 lejano a 301 km", 7.1, 10, "lejano", ptr(301), Advisory, "major-far"},
+		{"M7.5 lejano", 7.5, 10, "lejano", nil, Advisory, "major-far"},
+
+		// M7.6
+		{"M7.6 local", 7.6, 10, "local", nil, Warning, "great-near"},
+		{"M7.6 regional", 7.6, 10, "regional", nil, Warning, "great-near"},
+		{"M7.6 lejano", 7.6, 10, "lejano", nil, Watch, "great-far"},
+		{"M7.8 lejano", 7.8, 10, "lejano", ptr(50), Watch, "great-far"},
+
+		// M7.9
+		{"M7.9 lejano", 7.9, 10, "lejano", nil, Warning, "basin-wide"},
+		{"M7.9 local", 7.9, 10, "local", nil, Warning, "basin-wide"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eq := models.Earthquake{
+				Magnitude:       tt.magnitude,
+				Depth:           tt.depth,
+				Oceano:          "Pacifico",
+				OceanoRegion:    tt.region,
+				CoastDistanceKm: tt.coastKm,
+			}
+			got := Evaluate(eq, DefaultRules)
+			if got.Level != tt.level || got.RuleID != tt.ruleID {
+				t.Errorf("Evaluate() = %s (%s), want %s (%s)", got.Level, got.RuleID, tt.level, tt.ruleID)
+			}
+		})
+	}
+}
+
+func TestEvaluateNoMatch(t *testing.T) {
+	table := []Rule{{ID: "big", Level: Warning, MinMagnitude: ptr(8)}}
+	got := Evaluate(models.Earthquake{Magnitude: 5}, table)
+	if got.Level != Information || got.RuleID != "" {
+		t.Errorf("Evaluate() = %s (%s), want information without rule", got.Level, got.RuleID)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []Rule
+		err   string // "" = válida
+	}{
+		{"por defecto", DefaultRules, ""},
+		{"vacía", nil, ""},
+		{"sin id", []Rule{{Level: Watch}}, "has no id"},
+		{"id duplicado", []Rule{{ID: "a", Level: Watch}, {ID: "a", Level: Warning}}, "duplicate rule id"},
+		{"nivel vacío", []Rule{{ID: "a"}}, "invalid level"},
+		{"nivel desconocido", []Rule{{ID: "a", Level: "alarm"}}, "invalid level"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.rules)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+// Package tsunami estima tiempos de llegada de tsunami a puntos de pronóstico de la costa colombiana
+//
+// Usa el modelo de aguas someras (velocidad = √(g·h)) sobre una grilla batimétrica y
+// calcula el camino de menor tiempo desde el epicentro con Dijkstra. La grilla embebida
+// (bathymetry.json, 0.25°) es una aproximación gruesa construida a partir de la distancia
+// a la costa; para estimaciones operativas se puede reemplazar con LoadGrid por una grilla
+// remuestreada de GEBCO/ETOPO con el mismo formato.
+package tsunami
+
+import (
+	"container/heap"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+const (
+	// Aceleración de la gravedad en m/s²
+	gravity = 9.81
+
+	// Celdas que se buscan alrededor de un epicentro en tierra para encontrar agua
+	maxSourceSnapCells = 1
+
+	// Celdas que se buscan alrededor de un punto de pronóstico para encontrar agua
+	maxTargetSnapCells = 3
+)
+
+// Grid es una grilla batimétrica regular; las profundidades están en metros
+// y las celdas con profundidad <= 0 se consideran tierra
+type Grid struct {
+	MinLat   float64   `json:"minLat"`
+	MinLon   float64   `json:"minLon"`
+	CellSize float64   `json:"cellSize"` // grados
+	Rows     int       `json:"rows"`
+	Cols     int       `json:"cols"`
+	Depths   []float64 `json:"depths"` // fila por fila, de sur a norte y de oeste a este
+}
+
+// ForecastPoint es un punto de la costa para el que se estima la llegada del tsunami
+type ForecastPoint struct {
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+}
+
+// DefaultForecastPoints son los puntos de pronóstico usados si no se configura otra lista
+var DefaultForecastPoints = []ForecastPoint{
+	{Name: "Tumaco", Lat: 1.807, Lon: -78.765},
+	{Name: "Buenaventura", Lat: 3.883, Lon: -77.031},
+	{Name: "Guapi", Lat: 2.571, Lon: -77.886},
+	{Name: "Bahía Solano", Lat: 6.223, Lon: -77.403},
+	{Name: "Cartagena", Lat: 10.391, Lon: -75.514},
+	{Name: "Santa Marta", Lat: 11.241, Lon: -74.205},
+	{Name: "San Andrés", Lat: 12.584, Lon: -81.700},
+}
+
+//go:embed bathymetry.json
+var bathymetryData []byte
+
+// Estimator calcula tiempos de viaje sobre una grilla hacia un conjunto de puntos
+type Estimator struct {
+	grid    *Grid
+	points  []ForecastPoint
+	targets []int // Celda de agua asociada a cada punto (-1 si no hay)
+}
+
+var (
+	mu        sync.RWMutex
+	estimator *Estimator
+)
+
+func init() {
+	grid, err := parseGrid(bathymetryData)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded bathymetry.json: %v", err))
+	}
+	estimator = NewEstimator(grid, DefaultForecastPoints)
+}
+
+// LoadGrid carga una grilla batimétrica desde un archivo JSON
+func LoadGrid(filePath string) (*Grid, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return parseGrid(data)
+}
+
+// parseGrid decodifica y valida una grilla batimétrica
+func parseGrid(data []byte) (*Grid, error) {
+	var grid Grid
+	if err := json.Unmarshal(data, &grid); err != nil {
+		return nil, err
+	}
+	if grid.Rows <= 0 || grid.Cols <= 0 || grid.CellSize <= 0 {
+		return nil, fmt.Errorf("invalid grid dimensions %dx%d (cell %g)", grid.Rows, grid.Cols, grid.CellSize)
+	}
+	if len(grid.Depths) != grid.Rows*grid.Cols {
+		return nil, fmt.Errorf("grid has %d depths, expected %d", len(grid.Depths), grid.Rows*grid.Cols)
+	}
+	return &grid, nil
+}
+
+// LoadForecastPoints carga una lista de puntos de pronóstico desde un archivo JSON
+func LoadForecastPoints(filePath string) ([]ForecastPoint, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []ForecastPoint
+	if err := json.Unmarshal(data, &points); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// Configure reemplaza la grilla y/o los puntos de pronóstico usados por EstimateArrivals
+// Un argumento nil conserva el valor actual.
+func Configure(grid *Grid, points []ForecastPoint) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if grid == nil {
+		grid = estimator.grid
+	}
+	if points == nil {
+		points = estimator.points
+	}
+	estimator = NewEstimator(grid, points)
+}
+
+// ForecastPoints retorna los puntos de pronóstico configurados
+func ForecastPoints() []ForecastPoint {
+	mu.RLock()
+	defer mu.RUnlock()
+	return estimator.points
+}
+
+// EstimateArrivals estima la llegada del tsunami a los puntos de pronóstico configurados
+func EstimateArrivals(eq models.Earthquake) []models.TsunamiETA {
+	mu.RLock()
+	e := estimator
+	mu.RUnlock()
+
+	return e.Estimate(eq)
+}
+
+// NewEstimator crea un estimador y asocia cada punto de pronóstico a su celda de agua más cercana
+func NewEstimator(grid *Grid, points []ForecastPoint) *Estimator {
+	e := &Estimator{
+		grid:    grid,
+		points:  points,
+		targets: make([]int, len(points)),
+	}
+	for i, p := range points {
+		e.targets[i] = grid.nearestWaterCell(p.Lat, p.Lon, maxTargetSnapCells)
+	}
+	return e
+}
+
+// Estimate calcula los tiempos de llegada desde el epicentro del sismo
+// Retorna nil si el epicentro no está sobre (o junto a) una celda de agua de la grilla.
+// Los puntos inalcanzables (otra cuenca, fuera de la grilla) se omiten.
+func (e *Estimator) Estimate(eq models.Earthquake) []models.TsunamiETA {
+	source := e.grid.nearestWaterCell(eq.Latitude, eq.Longitude, maxSourceSnapCells)
+	if source < 0 {
+		return nil
+	}
+
+	times := e.travelTimes(source)
+	epicenter := models.Point{Lat: eq.Latitude, Lon: eq.Longitude}
+
+	etas := make([]models.TsunamiETA, 0, len(e.points))
+	for i, point := range e.points {
+		target := e.targets[i]
+		if target < 0 || math.IsInf(times[target], 1) {
+			continue
+		}
+
+		travel := time.Duration(times[target] * float64(time.Second))
+		etas = append(etas, models.TsunamiETA{
+			Point:         point.Name,
+			TravelMinutes: math.Round(travel.Minutes()*10) / 10,
+			ArrivalTime:   eq.Time.Add(travel),
+			DistanceKm:    math.Round(geometry.Distance(epicenter, models.Point{Lat: point.Lat, Lon: point.Lon})*10) / 10,
+		})
+	}
+
+	sort.Slice(etas, func(i, j int) bool {
+		return etas[i].TravelMinutes < etas[j].TravelMinutes
+	})
+
+	return etas
+}
+
+// travelTimes calcula con Dijkstra el tiempo mínimo (segundos) desde la celda origen a cada celda
+// Se detiene en cuanto se han fijado todas las celdas de los puntos de pronóstico.
+func (e *Estimator) travelTimes(source int) []float64 {
+	g := e.grid
+	times := make([]float64, len(g.Depths))
+	for i := range times {
+		times[i] = math.Inf(1)
+	}
+	done := make([]bool, len(g.Depths))
+
+	pending := make(map[int]bool)
+	for _, target := range e.targets {
+		if target >= 0 {
+			pending[target] = true
+		}
+	}
+
+	times[source] = 0
+	queue := &cellQueue{{cell: source, time: 0}}
+
+	for queue.Len() > 0 && len(pending) > 0 {
+		current := heap.Pop(queue).(cellTime)
+		if done[current.cell] {
+			continue
+		}
+		done[current.cell] = true
+		delete(pending, current.cell)
+
+		row, col := current.cell/g.Cols, current.cell%g.Cols
+		lat, lon := g.cellCenter(row, col)
+		speed := waveSpeed(g.Depths[current.cell])
+
+		for dr := -1; dr <= 1; dr++ {
+			for dc := -1; dc <= 1; dc++ {
+				if dr == 0 && dc == 0 {
+					continue
+				}
+				r, c := row+dr, col+dc
+				if r < 0 || r >= g.Rows || c < 0 || c >= g.Cols {
+					continue
+				}
+				next := r*g.Cols + c
+				if done[next] || g.Depths[next] <= 0 {
+					continue
+				}
+
+				nLat, nLon := g.cellCenter(r, c)
+				distance := geometry.Distance(models.Point{Lat: lat, Lon: lon}, models.Point{Lat: nLat, Lon: nLon}) * 1000
+				t := current.time + distance/((speed+waveSpeed(g.Depths[next]))/2)
+
+				if t < times[next] {
+					times[next] = t
+					heap.Push(queue, cellTime{cell: next, time: t})
+				}
+			}
+		}
+	}
+
+	return times
+}
+
+// waveSpeed retorna la velocidad de onda en aguas someras (m/s) para una profundidad en metros
+func waveSpeed(depth float64) float64 {
+	return math.Sqrt(gravity * depth)
+}
+
+// cellCenter retorna la latitud y longitud del centro de una celda
+func (g *Grid) cellCenter(row, col int) (float64, float64) {
+	return g.MinLat + (float64(row)+0.5)*g.CellSize, g.MinLon + (float64(col)+0.5)*g.CellSize
+}
+
+// nearestWaterCell retorna la celda de agua más cercana al punto dentro de maxCells celdas (-1 si no hay)
+func (g *Grid) nearestWaterCell(lat, lon float64, maxCells int) int {
+	row := int(math.Floor((lat - g.MinLat) / g.CellSize))
+	col := int(math.Floor((lon - g.MinLon) / g.CellSize))
+	if row < 0 || row >= g.Rows || col < 0 || col >= g.Cols {
+		return -1
+	}
+
+	best := -1
+	bestDistance := math.Inf(1)
+	point := models.Point{Lat: lat, Lon: lon}
+
+	for r := row - maxCells; r <= row+maxCells; r++ {
+		for c := col - maxCells; c <= col+maxCells; c++ {
+			if r < 0 || r >= g.Rows || c < 0 || c >= g.Cols || g.Depths[r*g.Cols+c] <= 0 {
+				continue
+			}
+			cLat, cLon := g.cellCenter(r, c)
+			if d := geometry.Distance(point, models.Point{Lat: cLat, Lon: cLon}); d < bestDistance {
+				bestDistance = d
+				best = r*g.Cols + c
+			}
+		}
+	}
+
+	return best
+}
+
+// cellTime es una entrada de la cola de prioridad de Dijkstra
+type cellTime struct {
+	cell int
+	time float64
+}
+
+// cellQueue implementa heap.Interface ordenando por tiempo
+type cellQueue []cellTime
+
+func (q cellQueue) Len() int            { return len(q) }
+func (q cellQueue) Less(i, j int) bool  { return q[i].time < q[j].time }
+func (q cellQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *cellQueue) Push(x interface{}) { *q = append(*q, x.(cellTime)) }
+func (q *cellQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
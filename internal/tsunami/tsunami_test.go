@@ -0,0 +1,109 @@
+package tsunami
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/andresgallo/evida_backend_go/internal/geometry"
+	"github.com/andresgallo/evida_backend_go/internal/models"
+)
+
+// flatGrid crea una grilla de 0.25° con profundidad constante, con la fila 10 sobre el ecuador
+func flatGrid(rows, cols int, depth float64) *Grid {
+	grid := &Grid{
+		MinLat:   -2.625,
+		MinLon:   -90,
+		CellSize: 0.25,
+		Rows:     rows,
+		Cols:     cols,
+		Depths:   make([]float64, rows*cols),
+	}
+	for i := range grid.Depths {
+		grid.Depths[i] = depth
+	}
+	return grid
+}
+
+// setLand marca como tierra las celdas del rectángulo de filas y columnas dado
+func setLand(grid *Grid, minRow, maxRow, minCol, maxCol int) {
+	for r := minRow; r <= maxRow; r++ {
+		for c := minCol; c <= maxCol; c++ {
+			grid.Depths[r*grid.Cols+c] = 0
+		}
+	}
+}
+
+func TestEstimateFlatDepth(t *testing.T) {
+	const depth = 4000.0
+	grid := flatGrid(21, 41, depth)
+	source := models.Earthquake{Latitude: 0, Longitude: -89.875, Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	// Sobre el ecuador y sobre un meridiano el camino de la grilla es el círculo máximo
+	points := []ForecastPoint{
+		{Name: "este", Lat: 0, Lon: -80.125},
+		{Name: "norte", Lat: 2.5, Lon: -89.875},
+	}
+	etas := NewEstimator(grid, points).Estimate(source)
+	if len(etas) != len(points) {
+		t.Fatalf("Estimate() = %d ETAs, want %d", len(etas), len(points))
+	}
+
+	speed := math.Sqrt(gravity * depth)
+	for _, eta := range etas {
+		var point ForecastPoint
+		for _, p := range points {
+			if p.Name == eta.Point {
+				point = p
+			}
+		}
+		distance := geometry.Distance(models.Point{Lat: source.Latitude, Lon: source.Longitude}, models.Point{Lat: point.Lat, Lon: point.Lon})
+		want := distance * 1000 / speed / 60
+
+		if math.Abs(eta.TravelMinutes-want) > 0.1 {
+			t.Errorf("%s: TravelMinutes = %v, want %.1f (d/√(g·h))", eta.Point, eta.TravelMinutes, want)
+		}
+		if got := eta.ArrivalTime.Sub(source.Time).Minutes(); math.Abs(got-want) > 0.1 {
+			t.Errorf("%s: ArrivalTime is %.1f min after origin, want %.1f", eta.Point, got, want)
+		}
+		if math.Abs(eta.DistanceKm-distance) > 0.05 {
+			t.Errorf("%s: DistanceKm = %v, want %.1f", eta.Point, eta.DistanceKm, distance)
+		}
+	}
+	if etas[0].Point != "norte" {
+		t.Errorf("Estimate() first ETA = %s, want the closest point first", etas[0].Point)
+	}
+}
+
+func TestEstimateUnreachable(t *testing.T) {
+	grid := flatGrid(21, 41, 3000)
+	setLand(grid, 0, 20, 20, 20) // Muro de tierra que separa dos cuencas
+	setLand(grid, 5, 15, 30, 40) // Bloque de tierra al este
+	points := []ForecastPoint{
+		{Name: "misma cuenca", Lat: 0, Lon: -86},
+		{Name: "otra cuenca", Lat: 0, Lon: -83},
+		{Name: "tierra adentro", Lat: 0, Lon: -80.125},
+		{Name: "fuera de la grilla", Lat: 30, Lon: -86},
+	}
+	estimator := NewEstimator(grid, points)
+
+	etas := estimator.Estimate(models.Earthquake{Latitude: 0, Longitude: -89})
+	if len(etas) != 1 || etas[0].Point != "misma cuenca" {
+		t.Errorf("Estimate() = %v, want only the point in the same basin", etas)
+	}
+
+	// Epicentro en tierra a más de una celda del agua
+	if etas := estimator.Estimate(models.Earthquake{Latitude: 0, Longitude: -80.125}); etas != nil {
+		t.Errorf("Estimate() from land = %v, want nil", etas)
+	}
+
+	// Epicentro fuera de la grilla
+	if etas := estimator.Estimate(models.Earthquake{Latitude: 20, Longitude: -89}); etas != nil {
+		t.Errorf("Estimate() outside the grid = %v, want nil", etas)
+	}
+
+	// Epicentro en tierra junto a la costa: se usa la celda de agua vecina
+	if etas := estimator.Estimate(models.Earthquake{Latitude: 0, Longitude: -84.95}); len(etas) != 1 || etas[0].Point != "misma cuenca" {
+		t.Errorf("Estimate() from the coast = %v, want the point in the same basin", etas)
+	}
+}
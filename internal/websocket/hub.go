@@ -42,6 +42,7 @@ type Hub struct {
 
 // NewHub crea un nuevo hub de WebSocket
 func NewHub() *Hub {
+	connectedClients.Set(0)
 	return &Hub{
 		clients:    make(map[*Client]bool),
 		broadcast:  make(chan []byte, 256),
@@ -57,6 +58,7 @@ func (h *Hub) Run() {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client] = true
+			connectedClients.Set(float64(len(h.clients)))
 			h.mu.Unlock()
 			log.Printf("Client connected. Total clients: %d", len(h.clients))
 
@@ -66,38 +68,46 @@ func (h *Hub) Run() {
 				delete(h.clients, client)
 				close(client.send)
 			}
+			connectedClients.Set(float64(len(h.clients)))
 			h.mu.Unlock()
 			log.Printf("Client disconnected. Total clients: %d", len(h.clients))
 
 		case message := <-h.broadcast:
-			h.mu.RLock()
+			h.mu.Lock()
 			for client := range h.clients {
 				select {
 				case client.send <- message:
 				default:
 					close(client.send)
 					delete(h.clients, client)
+					slowClientDisconnects.Inc()
 				}
 			}
-			h.mu.RUnlock()
+			connectedClients.Set(float64(len(h.clients)))
+			h.mu.Unlock()
 		}
 	}
 }
 
 // BroadcastEarthquake envía un sismo a todos los clientes conectados
 func (h *Hub) BroadcastEarthquake(eq models.Earthquake) {
+	h.Broadcast("new_earthquake", eq)
+}
+
+// Broadcast envía un mensaje del tipo dado a todos los clientes conectados
+func (h *Hub) Broadcast(messageType string, data interface{}) {
 	message := Message{
-		Type: "new_earthquake",
-		Data: eq,
+		Type: messageType,
+		Data: data,
 	}
 
-	data, err := json.Marshal(message)
+	encoded, err := json.Marshal(message)
 	if err != nil {
-		log.Printf("Error marshaling earthquake: %v", err)
+		log.Printf("Error marshaling %s message: %v", messageType, err)
 		return
 	}
 
-	h.broadcast <- data
+	h.broadcast <- encoded
 }
 
 // GetClientCount retorna el número de clientes conectados
@@ -171,6 +181,7 @@ func (c *Client) writePump() {
 			if err := w.Close(); err != nil {
 				return
 			}
+			messagesSent.Add(float64(n + 1))
 
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
@@ -0,0 +1,22 @@
+package websocket
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Métricas de los clientes WebSocket
+var (
+	connectedClients = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "evida_websocket_clients",
+		Help: "Clientes WebSocket conectados.",
+	})
+	messagesSent = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "evida_websocket_messages_sent_total",
+		Help: "Mensajes escritos a los clientes WebSocket.",
+	})
+	slowClientDisconnects = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "evida_websocket_slow_client_disconnects_total",
+		Help: "Clientes desconectados por no leer los mensajes a tiempo (buffer lleno).",
+	})
+)